@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/fatih/color"
+	"github.com/flou/ecs/pkg/aws"
+	"github.com/spf13/cobra"
+)
+
+type applyOpts struct {
+	region      string
+	file        string
+	dryRun      bool
+	yes         bool
+	showSecrets bool
+}
+
+func buildApplyCmd() *cobra.Command {
+	var opts = applyOpts{}
+	var cmd = &cobra.Command{
+		Use:   "apply",
+		Short: "Bring ECS services to the state described in a YAML manifest",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandApply(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVarP(&opts.file, "file", "f", "", "Path of the YAML manifest describing the services")
+	cmd.MarkFlagRequired("file")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Only print the plan")
+	cmd.Flags().BoolVarP(&opts.yes, "yes", "y", false, "Do not ask for confirmation")
+	cmd.Flags().BoolVar(&opts.showSecrets, "show-secrets", false, "Print the values of environment variables that look like secrets")
+
+	return cmd
+}
+
+func printPlan(plan aws.ServicePlan, showSecrets bool) {
+	fmt.Printf(
+		"%s service %s (cluster %s, task definition %s:%d)\n",
+		color.YellowString("~"), color.YellowString(plan.Manifest.Service), plan.Manifest.Cluster,
+		*plan.TaskDefinition.Family, *plan.TaskDefinition.Revision,
+	)
+	for _, change := range plan.Changes {
+		mask := func(value string) string {
+			if change.Variable == "" {
+				return value
+			}
+			return aws.MaskSecret(change.Variable, value, showSecrets)
+		}
+		if change.Old == nil {
+			fmt.Printf("    %s %s: %q\n", color.GreenString("+"), change.Path, mask(change.New))
+			continue
+		}
+		fmt.Printf("    %s %s: %q -> %q\n", color.YellowString("~"), change.Path, mask(*change.Old), mask(change.New))
+	}
+	fmt.Println()
+}
+
+func runCommandApply(options applyOpts) error {
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+
+	manifest, err := aws.LoadManifest(options.file)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	plans := make([]aws.ServicePlan, 0)
+	unchanged := 0
+	for _, serviceManifest := range manifest.Services {
+		plan, err := aws.PlanService(client, serviceManifest)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		if len(plan.Changes) == 0 {
+			unchanged++
+			continue
+		}
+		printPlan(plan, options.showSecrets)
+		plans = append(plans, plan)
+	}
+	fmt.Printf("Plan: %d to change, %d unchanged.\n", len(plans), unchanged)
+	if len(plans) == 0 || options.dryRun {
+		return nil
+	}
+	if !options.yes && !confirm(fmt.Sprintf("Apply the changes to %d services?", len(plans))) {
+		return nil
+	}
+
+	failed := false
+	for _, plan := range plans {
+		if err := plan.Apply(client); err != nil {
+			fmt.Printf("%s Failed to update service %s: %s\n", color.RedString("[KO]"), plan.Manifest.Service, err.Error())
+			failed = true
+			continue
+		}
+		fmt.Printf("%s Service %s updated\n", color.GreenString("[OK]"), plan.Manifest.Service)
+	}
+	if failed {
+		os.Exit(1)
+	}
+	return nil
+}
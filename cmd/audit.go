@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/fatih/color"
+	"github.com/flou/ecs/pkg/aws"
+)
+
+func runCommandAudit(options instanceOpts) error {
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+
+	var maxAge time.Duration
+	if options.maxAge != "" {
+		duration, err := parseDuration(options.maxAge)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		maxAge = duration
+	}
+	recommendedAMI := aws.RecommendedAMI(client, options.amiParameter)
+	fmt.Printf("Recommended ECS-optimized AMI: %s\n\n", color.YellowString(recommendedAMI))
+
+	clusterNames := aws.ListClusters(client, options.clusterFilter)
+	for _, cluster := range aws.DescribeClusters(client, clusterNames) {
+		instances := aws.ListContainerInstances(client, *cluster.ClusterName)
+		fmt.Printf("--- CLUSTER: %s (%d registered instances)\n", *cluster.ClusterName, len(instances))
+		if len(instances) == 0 {
+			fmt.Println()
+			continue
+		}
+
+		minAgentVersion, minDockerVersion := options.minAgentVersion, options.minDockerVersion
+		for _, cinst := range instances {
+			if options.minAgentVersion == "" && compareVersions(*cinst.VersionInfo.AgentVersion, minAgentVersion) > 0 {
+				minAgentVersion = *cinst.VersionInfo.AgentVersion
+			}
+			if options.minDockerVersion == "" && compareVersions(dockerVersion(&cinst), minDockerVersion) > 0 {
+				minDockerVersion = dockerVersion(&cinst)
+			}
+		}
+
+		var outdatedAgents, outdatedDocker, outdatedAMIs, oldInstances, flagged int
+		for _, cinst := range instances {
+			findings := make([]string, 0)
+			if compareVersions(*cinst.VersionInfo.AgentVersion, minAgentVersion) < 0 {
+				findings = append(findings, fmt.Sprintf("agent %s < %s", *cinst.VersionInfo.AgentVersion, minAgentVersion))
+				outdatedAgents++
+			}
+			if *cinst.AgentConnected == false {
+				findings = append(findings, "agent disconnected")
+			}
+			if compareVersions(dockerVersion(&cinst), minDockerVersion) < 0 {
+				findings = append(findings, fmt.Sprintf("docker %s < %s", dockerVersion(&cinst), minDockerVersion))
+				outdatedDocker++
+			}
+			if amiID := aws.FindAttribute(cinst.Attributes, "ecs.ami-id").Value; amiID != nil && *amiID != recommendedAMI {
+				findings = append(findings, fmt.Sprintf("AMI %s is not the recommended AMI", *amiID))
+				outdatedAMIs++
+			}
+			age := time.Since(*cinst.RegisteredAt)
+			if maxAge > 0 && age > maxAge {
+				findings = append(findings, fmt.Sprintf("registered %.1f days ago", age.Hours()/24))
+				oldInstances++
+			}
+			if len(findings) == 0 {
+				fmt.Printf("%s  %-20s\n", color.GreenString("[OK]  "), *cinst.Ec2InstanceId)
+				continue
+			}
+			flagged++
+			fmt.Printf("%s  %-20s  %s\n", color.RedString("[KO]  "), *cinst.Ec2InstanceId, strings.Join(findings, ", "))
+		}
+		fmt.Printf(
+			"Summary: %d/%d instances to update (%d outdated agents, %d outdated Docker, %d outdated AMIs, %d too old)\n\n",
+			flagged, len(instances), outdatedAgents, outdatedDocker, outdatedAMIs, oldInstances,
+		)
+	}
+	return nil
+}
+
+func dockerVersion(containerInstance *ecs.ContainerInstance) string {
+	return strings.TrimPrefix(*containerInstance.VersionInfo.DockerVersion, "DockerVersion: ")
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/fatih/color"
+	"github.com/flou/ecs/pkg/aws"
+	"github.com/spf13/cobra"
+)
+
+type autoscalingOpts struct {
+	region      string
+	cluster     string
+	service     string
+	minCapacity int64
+	maxCapacity int64
+}
+
+func buildAutoscalingCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "autoscaling",
+		Short: "Manage the Service Auto Scaling of your ECS services",
+	}
+
+	cmd.AddCommand(
+		buildAutoscalingSetCmd(),
+		buildAutoscalingSuspendCmd(true),
+		buildAutoscalingSuspendCmd(false),
+	)
+	return cmd
+}
+
+func addAutoscalingFlags(cmd *cobra.Command, opts *autoscalingOpts) {
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVarP(&opts.cluster, "cluster", "c", "", "Name of the ECS cluster")
+	cmd.MarkFlagRequired("cluster")
+	cmd.Flags().StringVarP(&opts.service, "service", "s", "", "Name of the ECS service")
+	cmd.MarkFlagRequired("service")
+}
+
+func buildAutoscalingSetCmd() *cobra.Command {
+	var opts = autoscalingOpts{}
+	var cmd = &cobra.Command{
+		Use:   "set",
+		Short: "Set the minimum and maximum number of tasks of the service",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandAutoscalingSet(opts)
+		},
+	}
+
+	addAutoscalingFlags(cmd, &opts)
+	cmd.Flags().Int64Var(&opts.minCapacity, "min", -1, "Minimum number of tasks")
+	cmd.Flags().Int64Var(&opts.maxCapacity, "max", -1, "Maximum number of tasks")
+
+	return cmd
+}
+
+func buildAutoscalingSuspendCmd(suspend bool) *cobra.Command {
+	var opts = autoscalingOpts{}
+	var cmd = &cobra.Command{
+		Use:   "resume",
+		Short: "Resume the scaling activities of the service",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandAutoscalingSuspend(opts, suspend)
+		},
+	}
+	if suspend {
+		cmd.Use = "suspend"
+		cmd.Short = "Suspend the scaling activities of the service"
+	}
+
+	addAutoscalingFlags(cmd, &opts)
+
+	return cmd
+}
+
+func runCommandAutoscalingSet(options autoscalingOpts) error {
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+
+	if _, err := aws.FindService(client, options.cluster, options.service); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	var minCapacity, maxCapacity *int64
+	if options.minCapacity >= 0 {
+		minCapacity = &options.minCapacity
+	}
+	if options.maxCapacity >= 0 {
+		maxCapacity = &options.maxCapacity
+	}
+	target := aws.ScalableTarget(client, options.cluster, options.service)
+	if target == nil && (minCapacity == nil || maxCapacity == nil) {
+		fmt.Println("Both --min and --max are required to enable Service Auto Scaling")
+		os.Exit(1)
+	}
+	aws.RegisterScalableTarget(client, options.cluster, options.service, minCapacity, maxCapacity, nil)
+
+	target = aws.ScalableTarget(client, options.cluster, options.service)
+	fmt.Printf(
+		"Service %s successfully updated: min %d / max %d\n",
+		color.YellowString(options.service), *target.MinCapacity, *target.MaxCapacity,
+	)
+	return nil
+}
+
+func runCommandAutoscalingSuspend(options autoscalingOpts, suspend bool) error {
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+
+	if aws.ScalableTarget(client, options.cluster, options.service) == nil {
+		fmt.Printf("Service %s does not use Service Auto Scaling\n", options.service)
+		os.Exit(1)
+	}
+	aws.SuspendScaling(client, options.cluster, options.service, suspend)
+	state := "resumed"
+	if suspend {
+		state = "suspended"
+	}
+	fmt.Printf("Scaling activities of service %s %s\n", color.YellowString(options.service), state)
+	return nil
+}
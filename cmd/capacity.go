@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/flou/ecs/pkg/aws"
+	"github.com/spf13/cobra"
+)
+
+type capacityOpts struct {
+	region        string
+	clusterFilter string
+}
+
+func buildCapacityCmd() *cobra.Command {
+	var opts = capacityOpts{}
+	var cmd = &cobra.Command{
+		Use:   "capacity",
+		Short: "Report the CPU and memory capacity of your ECS clusters",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandCapacity(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVarP(&opts.clusterFilter, "cluster", "c", "", "Filter by the name of the ECS cluster")
+
+	return cmd
+}
+
+func runCommandCapacity(options capacityOpts) error {
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+
+	clusterNames := aws.ListClusters(client, options.clusterFilter)
+	for _, cluster := range aws.DescribeClusters(client, clusterNames) {
+		containerInstances := aws.ListContainerInstances(client, *cluster.ClusterName)
+		fmt.Printf("--- CLUSTER: %s (%d registered instances)\n", *cluster.ClusterName, len(containerInstances))
+		if len(containerInstances) == 0 {
+			fmt.Println()
+			continue
+		}
+
+		instances := make([]aws.InstanceResources, 0)
+		byType := make(map[string]*aws.InstanceResources)
+		counts := make(map[string]int)
+		total := aws.InstanceResources{}
+		for _, cinst := range containerInstances {
+			resources := aws.ContainerInstanceResources(&cinst)
+			instances = append(instances, resources)
+			if _, ok := byType[resources.InstanceType]; !ok {
+				byType[resources.InstanceType] = &aws.InstanceResources{InstanceType: resources.InstanceType}
+			}
+			for _, sum := range []*aws.InstanceResources{byType[resources.InstanceType], &total} {
+				sum.RegisteredCPU += resources.RegisteredCPU
+				sum.RemainingCPU += resources.RemainingCPU
+				sum.RegisteredMemory += resources.RegisteredMemory
+				sum.RemainingMemory += resources.RemainingMemory
+			}
+			counts[resources.InstanceType]++
+		}
+
+		instanceTypes := make([]string, 0)
+		for instanceType := range byType {
+			instanceTypes = append(instanceTypes, instanceType)
+		}
+		sort.Strings(instanceTypes)
+		fmt.Printf("%-14s  %9s  %24s  %24s\n", "INSTANCE TYPE", "INSTANCES", "CPU:used/registered", "MEM:used/registered")
+		for _, instanceType := range instanceTypes {
+			printCapacityLine(instanceType, counts[instanceType], byType[instanceType])
+		}
+		printCapacityLine("TOTAL", len(instances), &total)
+		fmt.Println()
+
+		var largestCPU, largestMemory aws.InstanceResources
+		for _, instance := range instances {
+			if !instance.Active {
+				continue
+			}
+			if instance.RemainingCPU > largestCPU.RemainingCPU {
+				largestCPU = instance
+			}
+			if instance.RemainingMemory > largestMemory.RemainingMemory {
+				largestMemory = instance
+			}
+		}
+		if largestCPU.InstanceID != "" {
+			fmt.Printf(
+				"Largest task by CPU:    CPU %d / MEM %d (%s)\n",
+				largestCPU.RemainingCPU, largestCPU.RemainingMemory, largestCPU.InstanceID,
+			)
+		}
+		if largestMemory.InstanceID != "" {
+			fmt.Printf(
+				"Largest task by memory: CPU %d / MEM %d (%s)\n",
+				largestMemory.RemainingCPU, largestMemory.RemainingMemory, largestMemory.InstanceID,
+			)
+		}
+		fmt.Println()
+
+		// Services placed on EC2 through a capacity provider strategy have no launch type
+		services := make([]ecs.Service, 0)
+		asgProviders := aws.AutoScalingGroupProviders(client, &cluster)
+		for _, svc := range aws.ListServices(client, *cluster.ClusterName, "", "") {
+			if aws.ServiceUsesEC2(&svc, &cluster, asgProviders) {
+				services = append(services, svc)
+			}
+		}
+		if len(services) == 0 {
+			continue
+		}
+		fmt.Printf("%-50s  %-35s  %6s  %6s  %7s  %10s\n", "SERVICE", "TASK DEFINITION", "CPU", "MEM", "RUNNING", "CAN ADD")
+		for _, svc := range services {
+			taskDefinition := aws.ServiceTaskDefinition(client, *svc.TaskDefinition)
+			cpu, memory := aws.TaskDefinitionResources(&taskDefinition)
+			var fits int64
+			for _, instance := range instances {
+				fits += instance.Fits(cpu, memory)
+			}
+			fmt.Printf(
+				"%-50s  %-35s  %6d  %6d  %7d  %10d\n",
+				*svc.ServiceName, fmt.Sprintf("%s:%d", *taskDefinition.Family, *taskDefinition.Revision),
+				cpu, memory, *svc.RunningCount, fits,
+			)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+func printCapacityLine(name string, count int, resources *aws.InstanceResources) {
+	usedCPU := resources.RegisteredCPU - resources.RemainingCPU
+	usedMemory := resources.RegisteredMemory - resources.RemainingMemory
+	fmt.Printf(
+		"%-14s  %9d  %24s  %24s\n", name, count,
+		fmt.Sprintf("%d/%d (%.1f%%)", usedCPU, resources.RegisteredCPU, percentage(usedCPU, resources.RegisteredCPU)),
+		fmt.Sprintf("%d/%d (%.1f%%)", usedMemory, resources.RegisteredMemory, percentage(usedMemory, resources.RegisteredMemory)),
+	)
+}
+
+func percentage(value, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(value) * 100 / float64(total)
+}
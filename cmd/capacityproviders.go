@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/flou/ecs/pkg/aws"
+	"github.com/spf13/cobra"
+)
+
+type capacityProvidersOpts struct {
+	region        string
+	clusterFilter string
+}
+
+func buildCapacityProvidersCmd() *cobra.Command {
+	var opts = capacityProvidersOpts{}
+	var cmd = &cobra.Command{
+		Use:   "capacity-providers",
+		Short: "List the capacity providers of your ECS clusters",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandCapacityProviders(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVarP(&opts.clusterFilter, "cluster", "c", "", "Filter by the name of the ECS cluster")
+
+	return cmd
+}
+
+func runCommandCapacityProviders(options capacityProvidersOpts) error {
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+
+	clusterNames := aws.ListClusters(client, options.clusterFilter)
+	for _, cluster := range aws.DescribeClusters(client, clusterNames) {
+		fmt.Printf("--- CLUSTER: %s (%d capacity providers)\n", *cluster.ClusterName, len(cluster.CapacityProviders))
+		fmt.Printf("Default strategy: %s\n", aws.FormatCapacityProviderStrategy(cluster.DefaultCapacityProviderStrategy))
+		if len(cluster.CapacityProviders) == 0 {
+			fmt.Println()
+			continue
+		}
+		fmt.Printf(
+			"%-30s  %-8s  %-40s  %-15s  %6s  %11s  %-21s\n",
+			"CAPACITY PROVIDER", "STATUS", "AUTO SCALING GROUP", "MANAGED SCALING",
+			"TARGET", "RESERVATION", "TERMINATION PROTECTION",
+		)
+		for _, provider := range aws.DescribeCapacityProviders(client, cluster.CapacityProviders) {
+			var (
+				asgName               = "-"
+				managedScaling        = "-"
+				targetCapacity        = "-"
+				reservation           = "-"
+				terminationProtection = "-"
+			)
+			if asg := provider.AutoScalingGroupProvider; asg != nil {
+				asgName = aws.AutoScalingGroupNameFromArn(*asg.AutoScalingGroupArn)
+				terminationProtection = string(asg.ManagedTerminationProtection)
+				if asg.ManagedScaling != nil {
+					managedScaling = string(asg.ManagedScaling.Status)
+					if asg.ManagedScaling.TargetCapacity != nil {
+						targetCapacity = fmt.Sprintf("%d%%", *asg.ManagedScaling.TargetCapacity)
+					}
+				}
+				if value, ok := aws.CapacityProviderReservation(client, *cluster.ClusterName, *provider.Name); ok {
+					reservation = fmt.Sprintf("%.0f%%", value)
+				}
+			}
+			fmt.Printf(
+				"%-30s  %-8s  %-40s  %-15s  %6s  %11s  %-21s\n",
+				*provider.Name, provider.Status, asgName, managedScaling,
+				targetCapacity, reservation, terminationProtection,
+			)
+		}
+		fmt.Println()
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/fatih/color"
+	"github.com/flou/ecs/pkg/aws"
+	"github.com/spf13/cobra"
+)
+
+type drainOpts struct {
+	region        string
+	clusterFilter string
+	wait          bool
+	timeout       time.Duration
+}
+
+func buildDrainCmd() *cobra.Command {
+	var opts = drainOpts{}
+	var cmd = &cobra.Command{
+		Use:   "drain INSTANCE_ID...",
+		Short: "Drain container instances so their tasks are rescheduled on other instances",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandDrain(opts, args, ecs.ContainerInstanceStatusDraining)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVarP(&opts.clusterFilter, "cluster", "c", "", "Filter by the name of the ECS cluster")
+	cmd.Flags().BoolVarP(&opts.wait, "wait", "w", false, "Wait until no service task is running on the instances")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 30*time.Minute, "Maximum time to wait for the instances to drain")
+
+	return cmd
+}
+
+func buildUndrainCmd() *cobra.Command {
+	var opts = drainOpts{}
+	var cmd = &cobra.Command{
+		Use:   "undrain INSTANCE_ID...",
+		Short: "Set draining container instances back to ACTIVE",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandDrain(opts, args, ecs.ContainerInstanceStatusActive)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVarP(&opts.clusterFilter, "cluster", "c", "", "Filter by the name of the ECS cluster")
+
+	return cmd
+}
+
+func runCommandDrain(options drainOpts, instanceIDs []string, status ecs.ContainerInstanceStatus) error {
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+
+	instancesByCluster := findContainerInstances(client, options.clusterFilter, instanceIDs)
+	clusterNames := make([]string, 0)
+	for clusterName := range instancesByCluster {
+		clusterNames = append(clusterNames, clusterName)
+	}
+	sort.Strings(clusterNames)
+
+	for _, clusterName := range clusterNames {
+		containerInstanceArns := make([]string, 0)
+		for _, cinst := range instancesByCluster[clusterName] {
+			containerInstanceArns = append(containerInstanceArns, *cinst.ContainerInstanceArn)
+		}
+		aws.UpdateContainerInstancesState(client, clusterName, containerInstanceArns, status)
+		for _, cinst := range instancesByCluster[clusterName] {
+			fmt.Printf(
+				"Container instance %s (%s) is now %s\n",
+				color.YellowString(*cinst.Ec2InstanceId), clusterName, status,
+			)
+		}
+	}
+	if options.wait {
+		for _, clusterName := range clusterNames {
+			if _, err := waitForDrain(client, clusterName, instancesByCluster[clusterName], options.timeout); err != nil {
+				fmt.Println(color.RedString(err.Error()))
+				os.Exit(1)
+			}
+		}
+	}
+	return nil
+}
+
+// findContainerInstances finds the container instances running on EC2 instances, grouped by cluster
+func findContainerInstances(client *ecs.Client, clusterFilter string, instanceIDs []string) map[string][]ecs.ContainerInstance {
+	wanted := make(map[string]bool)
+	for _, instanceID := range instanceIDs {
+		wanted[instanceID] = true
+	}
+	instancesByCluster := make(map[string][]ecs.ContainerInstance)
+	clusterNames := aws.ListClusters(client, clusterFilter)
+	for _, cluster := range aws.DescribeClusters(client, clusterNames) {
+		for _, cinst := range aws.ListContainerInstances(client, *cluster.ClusterName) {
+			if wanted[*cinst.Ec2InstanceId] {
+				instancesByCluster[*cluster.ClusterName] = append(instancesByCluster[*cluster.ClusterName], cinst)
+				delete(wanted, *cinst.Ec2InstanceId)
+			}
+		}
+	}
+	if len(wanted) > 0 {
+		for instanceID := range wanted {
+			fmt.Printf("No container instance running on %s\n", instanceID)
+		}
+		os.Exit(1)
+	}
+	return instancesByCluster
+}
+
+// waitForDrain waits until no task of a service is running on the container instances, reporting
+// where the tasks of the services they were running are rescheduled, and returns these services.
+// Standalone tasks are not stopped by ECS on draining instances, they are reported but not waited
+// for. It fails when the instances are not drained before the timeout, if any
+func waitForDrain(client *ecs.Client, clusterName string, containerInstances []ecs.ContainerInstance, timeout time.Duration) ([]string, error) {
+	since := time.Now()
+	containerInstanceArns := make([]string, 0)
+	for _, cinst := range containerInstances {
+		containerInstanceArns = append(containerInstanceArns, *cinst.ContainerInstanceArn)
+	}
+	services := make(map[string]bool)
+	rescheduled := make(map[string]bool)
+	standalone := make(map[string]bool)
+	for {
+		remaining := 0
+		for _, cinst := range aws.DescribeContainerInstances(client, clusterName, containerInstanceArns) {
+			if *cinst.RunningTasksCount == 0 {
+				fmt.Printf("%s: no task running\n", *cinst.Ec2InstanceId)
+				continue
+			}
+			groups := make(map[string]int)
+			serviceTasks := 0
+			for _, task := range aws.ListTasks(client, clusterName, aws.TaskFilter{ContainerInstance: *cinst.ContainerInstanceArn}) {
+				if !strings.HasPrefix(*task.Group, "service:") {
+					if !standalone[*task.TaskArn] {
+						fmt.Printf(
+							"%s: standalone task %s (%s) is not stopped by draining, stop it with `ecs stop`\n",
+							*cinst.Ec2InstanceId, aws.TaskIDFromArn(*task.TaskArn), *task.Group,
+						)
+						standalone[*task.TaskArn] = true
+					}
+					continue
+				}
+				groups[*task.Group]++
+				services[strings.TrimPrefix(*task.Group, "service:")] = true
+				serviceTasks++
+			}
+			remaining += serviceTasks
+			if serviceTasks == 0 {
+				fmt.Printf("%s: no service task running\n", *cinst.Ec2InstanceId)
+				continue
+			}
+			details := make([]string, 0)
+			for group, count := range groups {
+				details = append(details, fmt.Sprintf("%s x%d", group, count))
+			}
+			sort.Strings(details)
+			fmt.Printf(
+				"%s: %d service tasks remaining (%s)\n",
+				*cinst.Ec2InstanceId, serviceTasks, strings.Join(details, ", "),
+			)
+		}
+
+		var instanceIds map[string]string
+		for service := range services {
+			for _, task := range aws.ListTasks(client, clusterName, aws.TaskFilter{Service: service}) {
+				if rescheduled[*task.TaskArn] || task.CreatedAt.Before(since) {
+					continue
+				}
+				if instanceIds == nil {
+					instanceIds = aws.Ec2InstanceIds(client, clusterName)
+				}
+				placement := "FARGATE"
+				if task.ContainerInstanceArn != nil {
+					placement = instanceIds[*task.ContainerInstanceArn]
+				}
+				fmt.Printf(
+					"  service %s: task %s rescheduled on %s\n",
+					color.YellowString(service), aws.TaskIDFromArn(*task.TaskArn), placement,
+				)
+				rescheduled[*task.TaskArn] = true
+			}
+		}
+
+		if remaining == 0 {
+			fmt.Printf("All container instances of cluster %s are drained\n", clusterName)
+			serviceNames := make([]string, 0)
+			for service := range services {
+				serviceNames = append(serviceNames, service)
+			}
+			sort.Strings(serviceNames)
+			return serviceNames, nil
+		}
+		if timeout > 0 && time.Since(since) > timeout {
+			return nil, fmt.Errorf("Timed out after %s waiting for the container instances of cluster %s to drain", timeout, clusterName)
+		}
+		time.Sleep(10 * time.Second)
+	}
+}
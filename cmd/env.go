@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/fatih/color"
+	"github.com/flou/ecs/pkg/aws"
+	"github.com/spf13/cobra"
+)
+
+type envOpts struct {
+	region      string
+	cluster     string
+	service     string
+	container   string
+	showSecrets bool
+	wait        bool
+	timeout     time.Duration
+}
+
+type envDiffOpts struct {
+	region     string
+	service    string
+	clusters   []string
+	showValues bool
+}
+
+func buildEnvCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "env",
+		Short: "Manage the environment of the containers of your ECS services",
+	}
+
+	cmd.AddCommand(
+		buildEnvDiffCmd(),
+		buildEnvGetCmd(),
+		buildEnvSetCmd(),
+		buildEnvUnsetCmd(),
+	)
+	return cmd
+}
+
+func buildEnvDiffCmd() *cobra.Command {
+	var opts = envDiffOpts{}
+	var cmd = &cobra.Command{
+		Use:   "diff",
+		Short: "Compare the environment variables and secrets of a service across ECS clusters",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandEnvDiff(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVarP(&opts.service, "service", "s", "", "Name of the ECS service")
+	cmd.MarkFlagRequired("service")
+	cmd.Flags().StringSliceVar(&opts.clusters, "clusters", []string{}, "Names of the ECS clusters to compare")
+	cmd.MarkFlagRequired("clusters")
+	cmd.Flags().BoolVar(&opts.showValues, "show-values", false, "Print the values, the ones that look like secrets being masked")
+
+	return cmd
+}
+
+func addEnvFlags(cmd *cobra.Command, opts *envOpts) {
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVarP(&opts.cluster, "cluster", "c", "", "Name of the ECS cluster")
+	cmd.MarkFlagRequired("cluster")
+	cmd.Flags().StringVarP(&opts.service, "service", "s", "", "Name of the ECS service")
+	cmd.MarkFlagRequired("service")
+	cmd.Flags().StringVar(&opts.container, "container", "", "Name of the container, required when the task has several containers")
+	cmd.Flags().BoolVar(&opts.showSecrets, "show-secrets", false, "Print the values of environment variables that look like secrets")
+}
+
+func buildEnvGetCmd() *cobra.Command {
+	var opts = envOpts{}
+	var cmd = &cobra.Command{
+		Use:   "get",
+		Short: "Print the environment variables and secrets of the containers of a service",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandEnvGet(opts)
+		},
+	}
+
+	addEnvFlags(cmd, &opts)
+
+	return cmd
+}
+
+func buildEnvSetCmd() *cobra.Command {
+	var opts = envOpts{}
+	var cmd = &cobra.Command{
+		Use:   "set KEY=VALUE...",
+		Short: "Set environment variables of a container, deploying a new revision of the task definition",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			set := make(map[string]string)
+			for _, arg := range args {
+				parts := strings.SplitN(arg, "=", 2)
+				if len(parts) != 2 || parts[0] == "" {
+					fmt.Printf("Invalid environment variable %s, expected KEY=VALUE\n", arg)
+					os.Exit(1)
+				}
+				set[parts[0]] = parts[1]
+			}
+			return runCommandEnvUpdate(opts, set, nil)
+		},
+	}
+
+	addEnvFlags(cmd, &opts)
+	cmd.Flags().BoolVarP(&opts.wait, "wait", "w", false, "Wait for the deployment of the new revision to complete")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 15*time.Minute, "Maximum time to wait for the deployment")
+
+	return cmd
+}
+
+func buildEnvUnsetCmd() *cobra.Command {
+	var opts = envOpts{}
+	var cmd = &cobra.Command{
+		Use:   "unset KEY...",
+		Short: "Remove environment variables of a container, deploying a new revision of the task definition",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandEnvUpdate(opts, nil, args)
+		},
+	}
+
+	addEnvFlags(cmd, &opts)
+	cmd.Flags().BoolVarP(&opts.wait, "wait", "w", false, "Wait for the deployment of the new revision to complete")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 15*time.Minute, "Maximum time to wait for the deployment")
+
+	return cmd
+}
+
+func runCommandEnvGet(options envOpts) error {
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+
+	service, err := aws.FindService(client, options.cluster, options.service)
+	if err != nil {
+		os.Exit(1)
+	}
+	taskDefinition := aws.ServiceTaskDefinition(client, *service.TaskDefinition)
+	containers := taskDefinition.ContainerDefinitions
+	if options.container != "" {
+		container, err := aws.FindContainerDefinition(&taskDefinition, options.container)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		containers = []ecs.ContainerDefinition{*container}
+	}
+	fmt.Printf("--- %s (%s:%d)\n", *service.ServiceName, *taskDefinition.Family, *taskDefinition.Revision)
+	for _, container := range containers {
+		fmt.Printf("- Container: %s\n", *container.Name)
+		aws.PrintEnvironment(&container, options.showSecrets)
+	}
+	return nil
+}
+
+func runCommandEnvUpdate(options envOpts, set map[string]string, unset []string) error {
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+
+	update, err := aws.PlanEnvUpdate(client, options.cluster, options.service, options.container, set, unset)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	if len(update.Changes) == 0 {
+		fmt.Println("No changes to the environment of container " + update.Container)
+		return nil
+	}
+	fmt.Printf(
+		"%s container %s (service %s, task definition %s:%d)\n",
+		color.YellowString("~"), color.YellowString(update.Container), update.Service,
+		*update.TaskDefinition.Family, *update.TaskDefinition.Revision,
+	)
+	for _, change := range update.Changes {
+		switch {
+		case change.Old == nil:
+			fmt.Printf("    %s %s: %q\n", color.GreenString("+"), change.Name, aws.MaskSecret(change.Name, *change.New, options.showSecrets))
+		case change.New == nil:
+			fmt.Printf("    %s %s\n", color.RedString("-"), change.Name)
+		default:
+			fmt.Printf(
+				"    %s %s: %q -> %q\n", color.YellowString("~"), change.Name,
+				aws.MaskSecret(change.Name, *change.Old, options.showSecrets),
+				aws.MaskSecret(change.Name, *change.New, options.showSecrets),
+			)
+		}
+	}
+
+	taskDefinition, err := update.Apply(client)
+	if err != nil {
+		fmt.Printf("%s Failed to update service %s: %s\n", color.RedString("[KO]"), update.Service, err.Error())
+		os.Exit(1)
+	}
+	fmt.Printf(
+		"%s Service %s updated to task definition %s:%d\n",
+		color.GreenString("[OK]"), update.Service, *taskDefinition.Family, *taskDefinition.Revision,
+	)
+	if options.wait {
+		if err := waitForDeployment(client, update.Cluster, update.Service, *taskDefinition.TaskDefinitionArn, options.timeout); err != nil {
+			fmt.Println(color.RedString(err.Error()))
+			os.Exit(1)
+		}
+	}
+	return nil
+}
+
+// waitForDeployment waits until the only deployment of a service is the one of a task definition
+// and runs its desired count of tasks. It fails when the deployment is rolled back, when ECS
+// reports it as failed or when it does not complete before the timeout
+func waitForDeployment(client *ecs.Client, clusterName, serviceName, taskDefinitionArn string, timeout time.Duration) error {
+	since := time.Now()
+	for {
+		service, err := aws.FindService(client, clusterName, serviceName)
+		if err != nil {
+			os.Exit(1)
+		}
+		var deployment *ecs.Deployment
+		for index := range service.Deployments {
+			if *service.Deployments[index].TaskDefinition == taskDefinitionArn {
+				deployment = &service.Deployments[index]
+			}
+		}
+		// The deployment circuit breaker replaces a failed deployment with one of the previous revision
+		if deployment == nil {
+			return fmt.Errorf("No deployment of service %s uses the new revision, it was rolled back", serviceName)
+		}
+		for _, event := range service.Events {
+			if event.CreatedAt.After(since) && strings.Contains(*event.Message, "deployment failed") {
+				return fmt.Errorf("Deployment of service %s failed: %s", serviceName, *event.Message)
+			}
+		}
+		if len(service.Deployments) == 1 && *deployment.RunningCount == *deployment.DesiredCount {
+			fmt.Printf("Service %s is running the new revision\n", serviceName)
+			return nil
+		}
+		if time.Since(since) > timeout {
+			return fmt.Errorf("Timed out after %s waiting for the deployment of service %s", timeout, serviceName)
+		}
+		fmt.Printf(
+			"Waiting for service %s: running %d/%d, %d deployments\n",
+			color.YellowString(serviceName), *deployment.RunningCount, *deployment.DesiredCount, len(service.Deployments),
+		)
+		time.Sleep(10 * time.Second)
+	}
+}
+
+// envDiffCell describes the value of a variable in a cluster: "-" when it is not set, otherwise a
+// label shared by the clusters with the same value, or the value itself
+func envDiffCell(difference aws.EnvDifference, index int, showValues bool) string {
+	value := difference.Values[index]
+	if value == nil {
+		return "-"
+	}
+	if showValues {
+		if difference.Secret {
+			return *value
+		}
+		return aws.MaskSecret(difference.Name, *value, false)
+	}
+	// Labels follow the order in which the values first appear
+	labels := make(map[string]int)
+	for _, other := range difference.Values {
+		if other == nil {
+			continue
+		}
+		if _, ok := labels[*other]; !ok {
+			labels[*other] = len(labels)
+		}
+	}
+	return fmt.Sprintf("value %c", 'A'+labels[*value])
+}
+
+func runCommandEnvDiff(options envDiffOpts) error {
+	if len(options.clusters) < 2 {
+		fmt.Println("At least 2 clusters are required to compare the environments")
+		os.Exit(1)
+	}
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+
+	taskDefinitions := make([]ecs.TaskDefinition, 0, len(options.clusters))
+	for _, cluster := range options.clusters {
+		service, err := aws.FindService(client, cluster, options.service)
+		if err != nil {
+			os.Exit(1)
+		}
+		taskDefinition := aws.ServiceTaskDefinition(client, *service.TaskDefinition)
+		fmt.Printf("--- %s: %s (%s:%d)\n", cluster, options.service, *taskDefinition.Family, *taskDefinition.Revision)
+		taskDefinitions = append(taskDefinitions, taskDefinition)
+	}
+	fmt.Println()
+
+	differences := aws.DiffEnvironments(taskDefinitions)
+	if len(differences) == 0 {
+		fmt.Println("No differences in the environment variables and secrets")
+		return nil
+	}
+
+	header := append([]string{"CONTAINER", "TYPE", "NAME"}, options.clusters...)
+	rows := [][]string{header}
+	for _, difference := range differences {
+		kind := "env"
+		if difference.Secret {
+			kind = "secret"
+		}
+		row := []string{difference.Container, kind, difference.Name}
+		for index := range options.clusters {
+			row = append(row, envDiffCell(difference, index, options.showValues))
+		}
+		rows = append(rows, row)
+	}
+	widths := make([]int, len(header))
+	for _, row := range rows {
+		for index, cell := range row {
+			if len(cell) > widths[index] {
+				widths[index] = len(cell)
+			}
+		}
+	}
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for index, cell := range row {
+			cells[index] = fmt.Sprintf("%-*s", widths[index], cell)
+		}
+		fmt.Println(strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+	fmt.Printf("\n%d differences\n", len(differences))
+	return nil
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/fatih/color"
+	"github.com/flou/ecs/pkg/aws"
+	"github.com/spf13/cobra"
+)
+
+type exportOpts struct {
+	region  string
+	cluster string
+	service string
+	format  string
+}
+
+func buildExportCmd() *cobra.Command {
+	var opts = exportOpts{}
+	var cmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export an ECS service and its task definition as infrastructure as code",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandExport(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVarP(&opts.cluster, "cluster", "c", "", "Name of the ECS cluster")
+	cmd.MarkFlagRequired("cluster")
+	cmd.Flags().StringVarP(&opts.service, "service", "s", "", "Name of the ECS service")
+	cmd.MarkFlagRequired("service")
+	cmd.Flags().StringVar(&opts.format, "format", "terraform", "Output format ("+strings.Join(aws.ExportFormats, ", ")+")")
+
+	return cmd
+}
+
+func runCommandExport(options exportOpts) error {
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+
+	service, err := aws.FindService(client, options.cluster, options.service)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	taskDefinition := aws.ServiceTaskDefinition(client, *service.TaskDefinition)
+	output, err := aws.ExportService(service, taskDefinition, options.format)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	fmt.Print(output)
+	for _, warning := range aws.ExportWarnings(service, taskDefinition) {
+		fmt.Fprintln(os.Stderr, color.YellowString("WARNING: "+warning))
+	}
+	return nil
+}
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/fatih/color"
 	"github.com/flou/ecs/pkg/aws"
 	"github.com/spf13/cobra"
 )
@@ -13,6 +15,7 @@ type imagesOpts struct {
 	clusterFilter string
 	serviceFilter string
 	serviceType   string
+	digests       bool
 }
 
 func buildImagesCmd() *cobra.Command {
@@ -29,6 +32,7 @@ func buildImagesCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&opts.clusterFilter, "cluster", "c", "", "Filter by the name of the ECS cluster")
 	cmd.Flags().StringVarP(&opts.serviceFilter, "service", "s", "", "Filter by the name of the ECS service")
 	cmd.Flags().StringVarP(&opts.serviceType, "type", "t", "", "Filter by service launch type")
+	cmd.Flags().BoolVar(&opts.digests, "digests", false, "Compare the digests of the images of the running tasks with the current digests of their tags, using the repositoryCredentials of private registries")
 
 	return cmd
 }
@@ -36,16 +40,87 @@ func buildImagesCmd() *cobra.Command {
 func runCommandImage(options imagesOpts) error {
 	client := ecs.New(aws.LoadAWSConfig(options.region))
 	clusterNames := aws.ListClusters(client, options.clusterFilter)
+	resolver := aws.NewImageResolver(client)
+	staleServices := make([]string, 0)
 
 	for _, cluster := range aws.DescribeClusters(client, clusterNames) {
 		services := aws.ListServices(client, *cluster.ClusterName, options.serviceFilter, options.serviceType)
 		fmt.Printf("--- CLUSTER: %s (%d services)\n", *cluster.ClusterName, len(services))
 		for _, svc := range services {
 			taskDefinition := aws.ServiceTaskDefinition(client, *svc.TaskDefinition)
+			// Tasks of other revisions, still running during a deployment, may use other tags
+			tasks := make([]ecs.Task, 0)
+			if options.digests {
+				for _, task := range aws.ListTasks(client, *cluster.ClusterName, aws.TaskFilter{Service: *svc.ServiceName}) {
+					if *task.TaskDefinitionArn == *svc.TaskDefinition {
+						tasks = append(tasks, task)
+					}
+				}
+			}
+			stale := false
 			for _, container := range taskDefinition.ContainerDefinitions {
 				fmt.Printf("%s: %s\n", *svc.ServiceName, *container.Image)
+				if options.digests && printImageDigests(resolver, tasks, &container) {
+					stale = true
+				}
+			}
+			if stale {
+				staleServices = append(staleServices, fmt.Sprintf("%s/%s", *cluster.ClusterName, *svc.ServiceName))
 			}
 		}
 	}
+	if options.digests {
+		fmt.Printf("\n%d services running stale images\n", len(staleServices))
+		for _, service := range staleServices {
+			fmt.Printf(" - %s\n", service)
+		}
+	}
 	return nil
 }
+
+// printImageDigests prints the digests of the image of a container run by the tasks of a
+// service, compared with the current digest of the image in its registry. It returns whether
+// some tasks run a stale image
+func printImageDigests(resolver *aws.ImageResolver, tasks []ecs.Task, container *ecs.ContainerDefinition) bool {
+	counts := make(map[string]int)
+	for _, task := range tasks {
+		for _, taskContainer := range task.Containers {
+			if *taskContainer.Name != *container.Name {
+				continue
+			}
+			digest := "unknown"
+			if taskContainer.ImageDigest != nil {
+				digest = *taskContainer.ImageDigest
+			}
+			counts[digest]++
+		}
+	}
+	credentialsParameter := ""
+	if container.RepositoryCredentials != nil && container.RepositoryCredentials.CredentialsParameter != nil {
+		credentialsParameter = *container.RepositoryCredentials.CredentialsParameter
+	}
+	current, err := resolver.Digest(*container.Image, credentialsParameter)
+	if err != nil {
+		fmt.Printf("   %s %s\n", color.YellowString("[UNKNOWN]"), err.Error())
+	} else {
+		fmt.Printf("   registry: %s\n", aws.ShortDigest(current))
+	}
+	digests := make([]string, 0, len(counts))
+	for digest := range counts {
+		digests = append(digests, digest)
+	}
+	sort.Strings(digests)
+	stale := false
+	for _, digest := range digests {
+		status := color.GreenString("[OK]")
+		switch {
+		case err != nil || digest == "unknown":
+			status = color.YellowString("[UNKNOWN]")
+		case digest != current:
+			status = color.RedString("[STALE]")
+			stale = true
+		}
+		fmt.Printf("   %s running: %s (%d tasks)\n", status, aws.ShortDigest(digest), counts[digest])
+	}
+	return stale
+}
@@ -15,9 +15,14 @@ import (
 )
 
 type instanceOpts struct {
-	region        string
-	clusterFilter string
-	longOutput    bool
+	region           string
+	clusterFilter    string
+	longOutput       bool
+	audit            bool
+	minAgentVersion  string
+	minDockerVersion string
+	maxAge           string
+	amiParameter     string
 }
 
 func buildInstancesCmd() *cobra.Command {
@@ -26,13 +31,26 @@ func buildInstancesCmd() *cobra.Command {
 		Use:   "instances",
 		Short: "List container instances in your ECS clusters",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.audit {
+				return runCommandAudit(opts)
+			}
 			return runCommandInstances(opts)
 		},
 	}
 	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
 	cmd.Flags().StringVarP(&opts.clusterFilter, "cluster", "c", "", "Filter by the name of the ECS cluster")
 	cmd.Flags().BoolVarP(&opts.longOutput, "long", "l", false, "Enable detailed output of containers instances")
+	cmd.Flags().BoolVar(&opts.audit, "audit", false, "Report outdated agents, Docker versions and AMIs, and old instances")
+	cmd.Flags().StringVar(&opts.minAgentVersion, "min-agent-version", "", "Minimum ECS agent version (defaults to the latest version running in the cluster)")
+	cmd.Flags().StringVar(&opts.minDockerVersion, "min-docker-version", "", "Minimum Docker version (defaults to the latest version running in the cluster)")
+	cmd.Flags().StringVar(&opts.maxAge, "max-age", "", "Report instances registered before this duration (e.g. 30d)")
+	cmd.Flags().StringVar(&opts.amiParameter, "ami-parameter", aws.DefaultAMIParameter, "SSM parameter of the recommended ECS-optimized AMI")
 
+	cmd.AddCommand(
+		buildDrainCmd(),
+		buildUndrainCmd(),
+		buildRotateCmd(),
+	)
 	return cmd
 }
 
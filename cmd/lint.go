@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/fatih/color"
+	"github.com/flou/ecs/pkg/aws"
+	"github.com/spf13/cobra"
+)
+
+type lintOpts struct {
+	region        string
+	taskDefFile   string
+	clusterFilter string
+	serviceFilter string
+	enable        []string
+	disable       []string
+	output        string
+	listRules     bool
+}
+
+func buildLintCmd() *cobra.Command {
+	var opts = lintOpts{}
+	var cmd = &cobra.Command{
+		Use:   "lint",
+		Short: "Check task definitions against best practices",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandLint(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVar(&opts.taskDefFile, "taskdef", "", "Path of a task definition JSON file")
+	cmd.Flags().StringVarP(&opts.clusterFilter, "cluster", "c", "", "Filter by the name of the ECS cluster")
+	cmd.Flags().StringVarP(&opts.serviceFilter, "service", "s", "", "Filter by the name of the ECS service")
+	cmd.Flags().StringSliceVar(&opts.enable, "enable", []string{}, "Only check these rules")
+	cmd.Flags().StringSliceVar(&opts.disable, "disable", []string{}, "Do not check these rules")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "text", "Output format (text or json)")
+	cmd.Flags().BoolVar(&opts.listRules, "list-rules", false, "List the rules and exit")
+
+	return cmd
+}
+
+// lintTarget is a task definition checked by `ecs lint`, with the service using it if any
+type lintTarget struct {
+	cluster        string
+	service        string
+	taskDefinition ecs.TaskDefinition
+}
+
+func runCommandLint(options lintOpts) error {
+	if options.listRules {
+		for _, rule := range aws.LintRules {
+			fmt.Printf("%-28s  %s\n", rule.Name, rule.Description)
+		}
+		return nil
+	}
+	if options.output != "text" && options.output != "json" {
+		fmt.Printf("Invalid output format %s, expected text or json\n", options.output)
+		os.Exit(1)
+	}
+
+	enabled := make(map[string]bool)
+	for _, rule := range aws.LintRules {
+		enabled[rule.Name] = len(options.enable) == 0
+	}
+	for _, names := range [][]string{options.enable, options.disable} {
+		for _, name := range names {
+			if _, ok := enabled[name]; !ok {
+				fmt.Printf("Unknown rule %s, see `ecs lint --list-rules`\n", name)
+				os.Exit(1)
+			}
+		}
+	}
+	for _, name := range options.enable {
+		enabled[name] = true
+	}
+	for _, name := range options.disable {
+		enabled[name] = false
+	}
+
+	targets := make([]lintTarget, 0)
+	if options.taskDefFile != "" {
+		taskDefinition, err := aws.ReadTaskDefinition(options.taskDefFile)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		targets = append(targets, lintTarget{taskDefinition: taskDefinition})
+	} else {
+		cfg := aws.LoadAWSConfig(options.region)
+		client := ecs.New(cfg)
+		clusterNames := aws.ListClusters(client, options.clusterFilter)
+		for _, cluster := range aws.DescribeClusters(client, clusterNames) {
+			for _, service := range aws.ListServices(client, *cluster.ClusterName, options.serviceFilter, "") {
+				targets = append(targets, lintTarget{
+					cluster:        *cluster.ClusterName,
+					service:        *service.ServiceName,
+					taskDefinition: aws.ServiceTaskDefinition(client, *service.TaskDefinition),
+				})
+			}
+		}
+	}
+
+	type serviceFinding struct {
+		Cluster string `json:"cluster,omitempty"`
+		Service string `json:"service,omitempty"`
+		aws.Finding
+	}
+	findings := make([]serviceFinding, 0)
+	for _, target := range targets {
+		for _, finding := range aws.LintTaskDefinition(target.taskDefinition, enabled) {
+			findings = append(findings, serviceFinding{Cluster: target.cluster, Service: target.service, Finding: finding})
+		}
+	}
+
+	if options.output == "json" {
+		content, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		fmt.Println(string(content))
+	} else {
+		for _, finding := range findings {
+			location := finding.TaskDefinition
+			if finding.Service != "" {
+				location = fmt.Sprintf("%s/%s (%s)", finding.Cluster, finding.Service, finding.TaskDefinition)
+			}
+			if finding.Container != "" {
+				location += " container " + finding.Container
+			}
+			fmt.Printf("%s %s: %s\n", color.RedString("[%s]", finding.Rule), location, finding.Message)
+		}
+		fmt.Printf("%d task definitions checked, %d findings\n", len(targets), len(findings))
+	}
+	if len(findings) > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/fatih/color"
+	"github.com/flou/ecs/pkg/aws"
+	"github.com/spf13/cobra"
+)
+
+type pauseOpts struct {
+	region        string
+	cluster       string
+	serviceFilter string
+	dryRun        bool
+	yes           bool
+}
+
+func addPauseFlags(cmd *cobra.Command, opts *pauseOpts) {
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVarP(&opts.cluster, "cluster", "c", "", "Name of the ECS cluster")
+	cmd.MarkFlagRequired("cluster")
+	cmd.Flags().StringVarP(&opts.serviceFilter, "service", "s", "", "Filter by the name of the ECS service")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Only print the services that would be updated")
+	cmd.Flags().BoolVarP(&opts.yes, "yes", "y", false, "Do not ask for confirmation")
+}
+
+func buildPauseCmd() *cobra.Command {
+	var opts = pauseOpts{}
+	var cmd = &cobra.Command{
+		Use:   "pause",
+		Short: "Scale the services of an ECS cluster down to zero, saving their DesiredCount",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandPause(opts)
+		},
+	}
+
+	addPauseFlags(cmd, &opts)
+
+	return cmd
+}
+
+func buildResumeCmd() *cobra.Command {
+	var opts = pauseOpts{}
+	var cmd = &cobra.Command{
+		Use:   "resume",
+		Short: "Restore the DesiredCount of the services paused with `ecs pause`",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandResume(opts)
+		},
+	}
+
+	addPauseFlags(cmd, &opts)
+
+	return cmd
+}
+
+func printPausePreview(services []ecs.Service, desiredCounts []int64) {
+	fmt.Printf("%-50s  %-15s  %7s\n", "SERVICE", "DESIRED", "RUNNING")
+	for index, service := range services {
+		fmt.Printf(
+			"%-50s  %-15s  %7d\n",
+			*service.ServiceName,
+			fmt.Sprintf("%d -> %d", *service.DesiredCount, desiredCounts[index]),
+			*service.RunningCount,
+		)
+	}
+	fmt.Println()
+}
+
+func runCommandPause(options pauseOpts) error {
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+
+	services := make([]ecs.Service, 0)
+	for _, service := range aws.ListServices(client, options.cluster, options.serviceFilter, "") {
+		if _, paused := aws.FindServiceTag(&service, aws.PausedDesiredCountTag); paused || *service.DesiredCount == 0 {
+			continue
+		}
+		services = append(services, service)
+	}
+	if len(services) == 0 {
+		fmt.Println("No service to pause")
+		return nil
+	}
+	fmt.Printf("--- CLUSTER: %s (pausing %d services)\n", options.cluster, len(services))
+	printPausePreview(services, make([]int64, len(services)))
+	if options.dryRun {
+		return nil
+	}
+	if !options.yes && !confirm(fmt.Sprintf("Pause %d services?", len(services))) {
+		return nil
+	}
+
+	failed := false
+	for index := range services {
+		service := &services[index]
+		tags := map[string]string{aws.PausedDesiredCountTag: strconv.FormatInt(*service.DesiredCount, 10)}
+		target := aws.ScalableTarget(client, options.cluster, *service.ServiceName)
+		suspendScaling := target != nil && !aws.ScalingSuspended(target)
+		if suspendScaling {
+			tags[aws.PausedAutoScalingTag] = "suspended"
+		}
+		if err := aws.TagService(client, service, tags); err != nil {
+			fmt.Printf("%s Failed to pause service %s: %s\n", color.RedString("[KO]"), *service.ServiceName, err.Error())
+			failed = true
+			continue
+		}
+		// Application Auto Scaling would scale the service back up to its minimum capacity
+		if suspendScaling {
+			aws.SuspendScaling(client, options.cluster, *service.ServiceName, true)
+		}
+		if err := aws.UpdateDesiredCount(client, options.cluster, *service.ServiceName, 0); err != nil {
+			fmt.Printf("%s Failed to pause service %s: %s\n", color.RedString("[KO]"), *service.ServiceName, err.Error())
+			failed = true
+			// The service still runs, it must not be seen as paused by a next run or by resume
+			if suspendScaling {
+				aws.SuspendScaling(client, options.cluster, *service.ServiceName, false)
+			}
+			if err := aws.UntagService(client, service, aws.PausedDesiredCountTag, aws.PausedAutoScalingTag); err != nil {
+				fmt.Printf("Failed to remove the tags of service %s: %s\n", *service.ServiceName, err.Error())
+			}
+			continue
+		}
+		fmt.Printf("%s Paused service %s (DesiredCount was %d)\n", color.GreenString("[OK]"), *service.ServiceName, *service.DesiredCount)
+	}
+	if failed {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func runCommandResume(options pauseOpts) error {
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+
+	services := make([]ecs.Service, 0)
+	desiredCounts := make([]int64, 0)
+	for _, service := range aws.ListServices(client, options.cluster, options.serviceFilter, "") {
+		value, paused := aws.FindServiceTag(&service, aws.PausedDesiredCountTag)
+		if !paused {
+			continue
+		}
+		desiredCount, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			fmt.Printf("Invalid value %q of tag %s on service %s\n", value, aws.PausedDesiredCountTag, *service.ServiceName)
+			os.Exit(1)
+		}
+		services = append(services, service)
+		desiredCounts = append(desiredCounts, desiredCount)
+	}
+	if len(services) == 0 {
+		fmt.Println("No paused service to resume")
+		return nil
+	}
+	fmt.Printf("--- CLUSTER: %s (resuming %d services)\n", options.cluster, len(services))
+	printPausePreview(services, desiredCounts)
+	if options.dryRun {
+		return nil
+	}
+	if !options.yes && !confirm(fmt.Sprintf("Resume %d services?", len(services))) {
+		return nil
+	}
+
+	failed := false
+	for index := range services {
+		service := &services[index]
+		if err := aws.UpdateDesiredCount(client, options.cluster, *service.ServiceName, desiredCounts[index]); err != nil {
+			fmt.Printf("%s Failed to resume service %s: %s\n", color.RedString("[KO]"), *service.ServiceName, err.Error())
+			failed = true
+			continue
+		}
+		if _, suspended := aws.FindServiceTag(service, aws.PausedAutoScalingTag); suspended {
+			aws.SuspendScaling(client, options.cluster, *service.ServiceName, false)
+		}
+		if err := aws.UntagService(client, service, aws.PausedDesiredCountTag, aws.PausedAutoScalingTag); err != nil {
+			fmt.Printf("%s Failed to remove the pause tags of service %s: %s\n", color.RedString("[KO]"), *service.ServiceName, err.Error())
+			failed = true
+			continue
+		}
+		fmt.Printf("%s Resumed service %s (DesiredCount=%d)\n", color.GreenString("[OK]"), *service.ServiceName, desiredCounts[index])
+	}
+	if failed {
+		os.Exit(1)
+	}
+	return nil
+}
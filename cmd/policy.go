@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/fatih/color"
+	"github.com/flou/ecs/pkg/aws"
+	"github.com/spf13/cobra"
+)
+
+type policyOpts struct {
+	region        string
+	policyFile    string
+	clusterFilter string
+	serviceFilter string
+	output        string
+}
+
+func buildPolicyCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "policy",
+		Short: "Check your ECS services against policies",
+	}
+
+	cmd.AddCommand(
+		buildPolicyCheckCmd(),
+	)
+	return cmd
+}
+
+func buildPolicyCheckCmd() *cobra.Command {
+	var opts = policyOpts{}
+	var cmd = &cobra.Command{
+		Use:   "check",
+		Short: "Check the services of your ECS clusters against the rules of a policy file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandPolicyCheck(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVar(&opts.policyFile, "policy", "", "Path of the YAML policy file")
+	cmd.MarkFlagRequired("policy")
+	cmd.Flags().StringVarP(&opts.clusterFilter, "cluster", "c", "", "Filter by the name of the ECS cluster")
+	cmd.Flags().StringVarP(&opts.serviceFilter, "service", "s", "", "Filter by the name of the ECS service")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "text", "Output format (text or json)")
+
+	return cmd
+}
+
+func runCommandPolicyCheck(options policyOpts) error {
+	if options.output != "text" && options.output != "json" {
+		fmt.Printf("Invalid output format %s, expected text or json\n", options.output)
+		os.Exit(1)
+	}
+	policy, err := aws.LoadPolicy(options.policyFile)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+
+	violations := make([]aws.Violation, 0)
+	checked := 0
+	clusterNames := aws.ListClusters(client, options.clusterFilter)
+	for _, cluster := range aws.DescribeClusters(client, clusterNames) {
+		for _, service := range aws.ListServices(client, *cluster.ClusterName, options.serviceFilter, "") {
+			taskDefinition := aws.ServiceTaskDefinition(client, *service.TaskDefinition)
+			violations = append(violations, policy.Check(*cluster.ClusterName, &service, &taskDefinition)...)
+			checked++
+		}
+	}
+
+	if options.output == "json" {
+		content, err := json.MarshalIndent(violations, "", "  ")
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		fmt.Println(string(content))
+	} else {
+		for _, violation := range violations {
+			fmt.Printf(
+				"%s %s/%s: %s\n",
+				color.RedString("[%s]", violation.Rule), violation.Cluster, violation.Service, violation.Message,
+			)
+		}
+		fmt.Printf("%d services checked against %d rules, %d violations\n", checked, len(policy.Rules), len(violations))
+	}
+	if len(violations) > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
@@ -31,11 +31,29 @@ func Execute(version string) error {
 	cmd.PersistentFlags().BoolVar(&opts.debug, "debug", false, "Enable debug mode")
 
 	cmd.AddCommand(
+		buildApplyCmd(),
+		buildAutoscalingCmd(),
+		buildCapacityCmd(),
+		buildCapacityProvidersCmd(),
+		buildDriftCmd(),
+		buildEnvCmd(),
 		buildEventsCmd(),
+		buildExportCmd(),
 		buildImagesCmd(),
 		buildInstancesCmd(),
+		buildLintCmd(),
+		buildPauseCmd(),
+		buildPolicyCmd(),
+		buildResumeCmd(),
+		buildScheduleCmd(),
+		buildSecretsCmd(),
 		buildServicesCmd(),
+		buildSnapshotCmd(),
+		buildSimulatePlacementCmd(),
 		buildTasksCmd(),
+		buildTaskDefinitionsCmd(),
+		buildRunCmd(),
+		buildStopCmd(),
 		buildUpdateCmd(),
 		buildCompletionCmd(),
 	)
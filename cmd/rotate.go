@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/fatih/color"
+	"github.com/flou/ecs/pkg/aws"
+	"github.com/spf13/cobra"
+)
+
+type rotateOpts struct {
+	region         string
+	cluster        string
+	olderThan      string
+	amiNot         string
+	amiParameter   string
+	batch          int
+	dryRun         bool
+	yes            bool
+	killStandalone bool
+	timeout        time.Duration
+}
+
+func buildRotateCmd() *cobra.Command {
+	var opts = rotateOpts{}
+	var cmd = &cobra.Command{
+		Use:   "rotate",
+		Short: "Replace container instances by draining and terminating them in batches",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandRotate(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVarP(&opts.cluster, "cluster", "c", "", "Name of the ECS cluster")
+	cmd.MarkFlagRequired("cluster")
+	cmd.Flags().StringVar(&opts.olderThan, "older-than", "", "Only replace instances registered before this duration (e.g. 30d)")
+	cmd.Flags().StringVar(&opts.amiNot, "ami-not", "", "Only replace instances not running this AMI ID (\"latest\" for the recommended ECS-optimized AMI)")
+	cmd.Flags().StringVar(&opts.amiParameter, "ami-parameter", aws.DefaultAMIParameter, "SSM parameter of the recommended ECS-optimized AMI")
+	cmd.Flags().IntVar(&opts.batch, "batch", 1, "Number of instances replaced at once")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Only print the instances that would be replaced")
+	cmd.Flags().BoolVarP(&opts.yes, "yes", "y", false, "Do not ask for confirmation")
+	cmd.Flags().BoolVar(&opts.killStandalone, "kill-standalone", false, "Terminate the instances even if they still run standalone tasks")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 30*time.Minute, "Maximum time to wait for each step of a batch before aborting the rotation")
+
+	return cmd
+}
+
+func runCommandRotate(options rotateOpts) error {
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+
+	var olderThan time.Duration
+	if options.olderThan != "" {
+		duration, err := parseDuration(options.olderThan)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		olderThan = duration
+	}
+	targetAMI := options.amiNot
+	if targetAMI == "latest" {
+		targetAMI = aws.RecommendedAMI(client, options.amiParameter)
+		fmt.Printf("Recommended ECS-optimized AMI: %s\n", color.YellowString(targetAMI))
+	}
+	if options.batch < 1 {
+		options.batch = 1
+	}
+
+	var instances []ecs.ContainerInstance
+	for _, cinst := range aws.ListContainerInstances(client, options.cluster) {
+		if *cinst.Status != string(ecs.ContainerInstanceStatusActive) {
+			continue
+		}
+		if olderThan > 0 && time.Since(*cinst.RegisteredAt) < olderThan {
+			continue
+		}
+		amiID := aws.FindAttribute(cinst.Attributes, "ecs.ami-id").Value
+		if targetAMI != "" && amiID != nil && *amiID == targetAMI {
+			continue
+		}
+		instances = append(instances, cinst)
+	}
+	if len(instances) == 0 {
+		fmt.Println("No container instance to replace")
+		return nil
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].RegisteredAt.Before(*instances[j].RegisteredAt)
+	})
+
+	fmt.Printf("--- CLUSTER: %s (replacing %d instances)\n", options.cluster, len(instances))
+	fmt.Printf("%-20s  %5s  %-21s  %10s\n", "INSTANCE ID", "TASKS", "AMI", "AGE")
+	for _, cinst := range instances {
+		amiID := "-"
+		if attr := aws.FindAttribute(cinst.Attributes, "ecs.ami-id"); attr.Value != nil {
+			amiID = *attr.Value
+		}
+		fmt.Printf(
+			"%-20s  %5d  %-21s  %10s\n", *cinst.Ec2InstanceId, *cinst.RunningTasksCount, amiID,
+			fmt.Sprintf("%4.1f days", time.Since(*cinst.RegisteredAt).Hours()/24),
+		)
+	}
+	fmt.Println()
+	if options.dryRun {
+		return nil
+	}
+	if !options.yes && !confirm(fmt.Sprintf("Replace %d instances, %d at a time?", len(instances), options.batch)) {
+		return nil
+	}
+
+	for index := 0; index < len(instances); index += options.batch {
+		upperBound := index + options.batch
+		if upperBound > len(instances) {
+			upperBound = len(instances)
+		}
+		batch := instances[index:upperBound]
+		registered := make(map[string]bool)
+		for _, cinst := range aws.ListContainerInstances(client, options.cluster) {
+			registered[*cinst.ContainerInstanceArn] = true
+		}
+
+		containerInstanceArns := make([]string, 0)
+		for _, cinst := range batch {
+			containerInstanceArns = append(containerInstanceArns, *cinst.ContainerInstanceArn)
+			fmt.Printf("Draining container instance %s\n", color.YellowString(*cinst.Ec2InstanceId))
+		}
+		aws.UpdateContainerInstancesState(client, options.cluster, containerInstanceArns, ecs.ContainerInstanceStatusDraining)
+		services, err := waitForDrain(client, options.cluster, batch, options.timeout)
+		if err == nil {
+			err = waitForServicesSteady(client, options.cluster, services, options.timeout)
+		}
+		if err != nil {
+			fmt.Println(color.RedString("%s, aborting the rotation", err.Error()))
+			fmt.Println("The container instances of the batch are still DRAINING, use `ecs instances undrain` to reactivate them")
+			os.Exit(1)
+		}
+		// Draining does not stop the tasks started outside of services, like the ones of `ecs run`
+		standalone := false
+		for _, cinst := range aws.DescribeContainerInstances(client, options.cluster, containerInstanceArns) {
+			if *cinst.RunningTasksCount > 0 {
+				fmt.Printf(
+					"Container instance %s still runs %d standalone tasks\n",
+					color.YellowString(*cinst.Ec2InstanceId), *cinst.RunningTasksCount,
+				)
+				standalone = true
+			}
+		}
+		if standalone && !options.killStandalone {
+			fmt.Println(color.RedString("Standalone tasks are still running, aborting the rotation"))
+			fmt.Println("Stop them with `ecs stop`, or use --kill-standalone to terminate the instances anyway")
+			os.Exit(1)
+		}
+
+		for _, cinst := range batch {
+			aws.TerminateInstanceInASG(client, *cinst.Ec2InstanceId)
+			fmt.Printf("Terminated instance %s\n", color.YellowString(*cinst.Ec2InstanceId))
+		}
+		if err := waitForRegistration(client, options.cluster, registered, len(batch), options.timeout); err != nil {
+			fmt.Println(color.RedString("%s, aborting the rotation", err.Error()))
+			fmt.Println("Check the launch template and the activity of the Auto Scaling group of the cluster")
+			os.Exit(1)
+		}
+	}
+	fmt.Println(color.GreenString("Replaced %d container instances", len(instances)))
+	return nil
+}
+
+// waitForServicesSteady waits until the services of the cluster run their desired count of tasks
+// and have reached a steady state. It fails when they are not steady before the timeout, if any
+func waitForServicesSteady(client *ecs.Client, clusterName string, services []string, timeout time.Duration) error {
+	if len(services) == 0 {
+		return nil
+	}
+	since := time.Now()
+	wanted := make(map[string]bool)
+	for _, service := range services {
+		wanted[service] = true
+	}
+	for {
+		pending := 0
+		for _, svc := range aws.ListServices(client, clusterName, "", "") {
+			if wanted[*svc.ServiceName] && !aws.ServiceOk(&svc) {
+				fmt.Printf(
+					"Waiting for service %s: running %d/%d\n",
+					color.YellowString(*svc.ServiceName), *svc.RunningCount, *svc.DesiredCount,
+				)
+				pending++
+			}
+		}
+		if pending == 0 {
+			fmt.Println("All services are steady")
+			return nil
+		}
+		if timeout > 0 && time.Since(since) > timeout {
+			return fmt.Errorf("Timed out after %s waiting for %d services of cluster %s to be steady", timeout, pending, clusterName)
+		}
+		time.Sleep(10 * time.Second)
+	}
+}
+
+// waitForRegistration waits until count new container instances, not in the registered set,
+// are active in the cluster. It fails when they are not registered before the timeout, if any
+func waitForRegistration(client *ecs.Client, clusterName string, registered map[string]bool, count int, timeout time.Duration) error {
+	since := time.Now()
+	for {
+		newInstances := make([]string, 0)
+		for _, cinst := range aws.ListContainerInstances(client, clusterName) {
+			if !registered[*cinst.ContainerInstanceArn] && *cinst.Status == string(ecs.ContainerInstanceStatusActive) {
+				newInstances = append(newInstances, *cinst.Ec2InstanceId)
+			}
+		}
+		if len(newInstances) >= count {
+			for _, instanceID := range newInstances {
+				fmt.Printf("New container instance %s registered\n", color.GreenString(instanceID))
+			}
+			return nil
+		}
+		if timeout > 0 && time.Since(since) > timeout {
+			return fmt.Errorf(
+				"Timed out after %s waiting for %d new container instances to register in cluster %s",
+				timeout, count-len(newInstances), clusterName,
+			)
+		}
+		fmt.Printf("Waiting for %d new container instances to register\n", count-len(newInstances))
+		time.Sleep(15 * time.Second)
+	}
+}
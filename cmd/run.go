@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/fatih/color"
+	"github.com/flou/ecs/pkg/aws"
+	"github.com/spf13/cobra"
+)
+
+type runOpts struct {
+	region         string
+	cluster        string
+	taskDefinition string
+	fromService    string
+	container      string
+	command        string
+	env            []string
+	launchType     string
+	wait           bool
+	logs           bool
+	timeout        time.Duration
+}
+
+func buildRunCmd() *cobra.Command {
+	var opts = runOpts{}
+	var cmd = &cobra.Command{
+		Use:   "run",
+		Short: "Run a one-off task in an ECS cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandRun(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVarP(&opts.cluster, "cluster", "c", "", "Name of the ECS cluster")
+	cmd.MarkFlagRequired("cluster")
+	cmd.Flags().StringVar(&opts.taskDefinition, "task-definition", "", "Family (and optional revision) of the task definition to run")
+	cmd.Flags().StringVar(&opts.fromService, "from-service", "", "Name of an ECS service to copy the task definition and network configuration from")
+	cmd.Flags().StringVar(&opts.container, "container", "", "Name of the container to override (defaults to the first container)")
+	cmd.Flags().StringVar(&opts.command, "command", "", "Override the command of the container")
+	cmd.Flags().StringArrayVarP(&opts.env, "env", "e", []string{}, "Set an environment variable in the container (KEY=VALUE)")
+	cmd.Flags().StringVarP(&opts.launchType, "type", "t", "", "Launch type of the task (fargate or ec2)")
+	cmd.Flags().BoolVarP(&opts.wait, "wait", "w", false, "Wait for the task to stop and exit with the exit code of the container")
+	cmd.Flags().BoolVar(&opts.logs, "logs", false, "Stream the logs of the container while waiting for the task to stop")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 0, "Maximum time to wait for the task to stop (0 for no limit)")
+
+	return cmd
+}
+
+func runCommandRun(options runOpts) error {
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+
+	startedBy := "ecs-run"
+	params := ecs.RunTaskInput{Cluster: &options.cluster, StartedBy: &startedBy}
+	if options.fromService != "" {
+		ecsService, err := aws.FindService(client, options.cluster, options.fromService)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		params.NetworkConfiguration = ecsService.NetworkConfiguration
+		params.PlatformVersion = ecsService.PlatformVersion
+		if len(ecsService.CapacityProviderStrategy) > 0 {
+			params.CapacityProviderStrategy = ecsService.CapacityProviderStrategy
+		} else {
+			params.LaunchType = ecsService.LaunchType
+		}
+		if options.taskDefinition == "" {
+			options.taskDefinition = *ecsService.TaskDefinition
+		}
+	}
+	if options.taskDefinition == "" {
+		fmt.Println("Either --task-definition or --from-service is required")
+		os.Exit(1)
+	}
+	if options.launchType != "" {
+		params.LaunchType = ecs.LaunchType(strings.ToUpper(options.launchType))
+		params.CapacityProviderStrategy = nil
+	}
+
+	taskDefinition := aws.ServiceTaskDefinition(client, options.taskDefinition)
+	params.TaskDefinition = taskDefinition.TaskDefinitionArn
+	container := taskDefinition.ContainerDefinitions[0]
+	if options.container != "" {
+		found := false
+		for _, c := range taskDefinition.ContainerDefinitions {
+			if *c.Name == options.container {
+				container, found = c, true
+			}
+		}
+		if !found {
+			fmt.Printf("No container %s in task definition %s\n", options.container, *taskDefinition.Family)
+			os.Exit(1)
+		}
+	}
+
+	override := ecs.ContainerOverride{Name: container.Name}
+	if options.command != "" {
+		command, err := aws.SplitCommand(options.command)
+		if err != nil {
+			fmt.Println("Invalid command: " + err.Error())
+			os.Exit(1)
+		}
+		override.Command = command
+	}
+	for _, env := range options.env {
+		parts := strings.SplitN(env, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			fmt.Printf("Invalid environment variable %s, expected KEY=VALUE\n", env)
+			os.Exit(1)
+		}
+		name, value := parts[0], parts[1]
+		override.Environment = append(override.Environment, ecs.KeyValuePair{Name: &name, Value: &value})
+	}
+	if override.Command != nil || override.Environment != nil {
+		params.Overrides = &ecs.TaskOverride{ContainerOverrides: []ecs.ContainerOverride{override}}
+	}
+
+	task := aws.RunTask(client, &params)
+	fmt.Printf("Started task %s (%s)\n", color.YellowString(*task.TaskArn), *taskDefinition.Family)
+	if !options.wait && !options.logs {
+		return nil
+	}
+
+	var stream *aws.LogStream
+	if options.logs {
+		stream = aws.ContainerLogStream(cfg, container, *task.TaskArn)
+		if stream == nil {
+			fmt.Printf("Container %s does not send its logs to CloudWatch Logs, they will not be streamed\n", *container.Name)
+		}
+	}
+	var lastStatus string
+	since := time.Now()
+	for {
+		task = aws.DescribeTask(client, options.cluster, *task.TaskArn)
+		if *task.LastStatus != lastStatus {
+			lastStatus = *task.LastStatus
+			fmt.Printf("Task is %s\n", lastStatus)
+		}
+		if stream != nil {
+			stream.PrintNewEvents()
+		}
+		if lastStatus == "STOPPED" {
+			break
+		}
+		if options.timeout > 0 && time.Since(since) > options.timeout {
+			fmt.Println(color.RedString("Timed out after %s waiting for task %s to stop, it is still running", options.timeout, *task.TaskArn))
+			os.Exit(1)
+		}
+		time.Sleep(5 * time.Second)
+	}
+	// The last log events reach CloudWatch Logs shortly after the task stops
+	if stream != nil {
+		time.Sleep(5 * time.Second)
+		stream.PrintNewEvents()
+	}
+
+	for _, c := range task.Containers {
+		if *c.Name != *container.Name {
+			continue
+		}
+		if c.ExitCode == nil {
+			reason := "unknown reason"
+			if task.StoppedReason != nil {
+				reason = *task.StoppedReason
+			}
+			fmt.Println(color.RedString("Task stopped: " + reason))
+			os.Exit(1)
+		}
+		if *c.ExitCode != 0 {
+			fmt.Println(color.RedString("Container %s exited with code %d", *c.Name, *c.ExitCode))
+			os.Exit(int(*c.ExitCode))
+		}
+		fmt.Println(color.GreenString("Container %s exited with code 0", *c.Name))
+	}
+	return nil
+}
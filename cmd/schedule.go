@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/applicationautoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/fatih/color"
+	"github.com/flou/ecs/pkg/aws"
+	"github.com/spf13/cobra"
+)
+
+type scheduleOpts struct {
+	region      string
+	cluster     string
+	service     string
+	cron        string
+	name        string
+	count       int64
+	minCapacity int64
+	maxCapacity int64
+}
+
+func buildScheduleCmd() *cobra.Command {
+	var opts = scheduleOpts{}
+	var cmd = &cobra.Command{
+		Use:   "schedule",
+		Short: "Schedule changes of the number of tasks of a service",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandSchedule(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVarP(&opts.cluster, "cluster", "c", "", "Name of the ECS cluster")
+	cmd.MarkFlagRequired("cluster")
+	cmd.Flags().StringVarP(&opts.service, "service", "s", "", "Name of the ECS service")
+	cmd.MarkFlagRequired("service")
+	cmd.Flags().StringVar(&opts.cron, "cron", "", "Cron expression of the schedule, in UTC (e.g. '0 20 * * 1-5')")
+	cmd.MarkFlagRequired("cron")
+	cmd.Flags().StringVar(&opts.name, "name", "", "Name of the scheduled action (generated from the schedule by default)")
+	cmd.Flags().Int64Var(&opts.count, "count", -1, "Number of tasks, sets both the minimum and maximum number of tasks")
+	cmd.Flags().Int64Var(&opts.minCapacity, "min", -1, "Minimum number of tasks")
+	cmd.Flags().Int64Var(&opts.maxCapacity, "max", -1, "Maximum number of tasks")
+
+	cmd.AddCommand(
+		buildScheduleListCmd(),
+		buildScheduleDeleteCmd(),
+	)
+	return cmd
+}
+
+func buildScheduleListCmd() *cobra.Command {
+	var opts = servicesOpts{}
+	var cmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the scheduled actions of the services running in your ECS clusters",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandScheduleList(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVarP(&opts.clusterFilter, "cluster", "c", "", "Filter by the name of the ECS cluster")
+	cmd.Flags().StringVarP(&opts.serviceFilter, "service", "s", "", "Filter by the name of the ECS service")
+
+	return cmd
+}
+
+func buildScheduleDeleteCmd() *cobra.Command {
+	var opts = scheduleOpts{}
+	var cmd = &cobra.Command{
+		Use:   "delete",
+		Short: "Delete a scheduled action of a service",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := aws.LoadAWSConfig(opts.region)
+			client := ecs.New(cfg)
+			aws.DeleteScheduledAction(client, opts.cluster, opts.service, opts.name)
+			fmt.Printf("Scheduled action %s of service %s deleted\n", opts.name, color.YellowString(opts.service))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVarP(&opts.cluster, "cluster", "c", "", "Name of the ECS cluster")
+	cmd.MarkFlagRequired("cluster")
+	cmd.Flags().StringVarP(&opts.service, "service", "s", "", "Name of the ECS service")
+	cmd.MarkFlagRequired("service")
+	cmd.Flags().StringVar(&opts.name, "name", "", "Name of the scheduled action")
+	cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+func runCommandSchedule(options scheduleOpts) error {
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+
+	if options.count >= 0 {
+		options.minCapacity, options.maxCapacity = options.count, options.count
+	}
+	if options.minCapacity < 0 && options.maxCapacity < 0 {
+		fmt.Println("One of --count, --min or --max is required")
+		os.Exit(1)
+	}
+	var minCapacity, maxCapacity *int64
+	if options.minCapacity >= 0 {
+		minCapacity = &options.minCapacity
+	}
+	if options.maxCapacity >= 0 {
+		maxCapacity = &options.maxCapacity
+	}
+	schedule, err := aws.CronSchedule(options.cron)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	if aws.ScalableTarget(client, options.cluster, options.service) == nil {
+		fmt.Printf(
+			"Service %s does not use Service Auto Scaling, enable it first with `ecs autoscaling set`\n",
+			options.service,
+		)
+		os.Exit(1)
+	}
+	if options.name == "" {
+		options.name = "ecs-" + strings.NewReplacer(" ", "_", "/", "-", "*", "x").Replace(options.cron)
+	}
+
+	aws.PutScheduledAction(client, options.cluster, options.service, options.name, schedule, minCapacity, maxCapacity)
+	fmt.Printf("Scheduled action %s of service %s set to %s\n", options.name, color.YellowString(options.service), schedule)
+	if next, ok := aws.NextRun(schedule, time.Now()); ok {
+		fmt.Printf("Next run: %s\n", next.Format("2006-01-02 15:04 MST"))
+	}
+	return nil
+}
+
+func runCommandScheduleList(options servicesOpts) error {
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+	now := time.Now()
+
+	clusterNames := aws.ListClusters(client, options.clusterFilter)
+	for _, cluster := range aws.DescribeClusters(client, clusterNames) {
+		services := aws.ListServices(client, *cluster.ClusterName, options.serviceFilter, "")
+		actionsByService := aws.ScheduledActions(client, *cluster.ClusterName)
+		fmt.Printf("--- CLUSTER: %s (%d services)\n", *cluster.ClusterName, len(services))
+		fmt.Printf(
+			"%-40s  %-30s  %-30s  %4s  %4s  %-20s\n",
+			"SERVICE", "NAME", "SCHEDULE", "MIN", "MAX", "NEXT RUN",
+		)
+		for _, svc := range services {
+			actions := actionsByService[*svc.ServiceName]
+			nextRuns := make(map[string]time.Time)
+			for _, action := range actions {
+				if next, ok := aws.NextRun(*action.Schedule, now); ok {
+					nextRuns[*action.ScheduledActionName] = next
+				}
+			}
+			sort.Slice(actions, func(i, j int) bool {
+				nextI, okI := nextRuns[*actions[i].ScheduledActionName]
+				nextJ, okJ := nextRuns[*actions[j].ScheduledActionName]
+				if okI != okJ {
+					return okI
+				}
+				return nextI.Before(nextJ)
+			})
+			for _, action := range actions {
+				nextRun := "-"
+				if next, ok := nextRuns[*action.ScheduledActionName]; ok {
+					nextRun = next.Format("2006-01-02 15:04 MST")
+				}
+				fmt.Printf(
+					"%-40s  %-30s  %-30s  %4s  %4s  %-20s\n",
+					*svc.ServiceName, *action.ScheduledActionName, *action.Schedule,
+					capacity(action.ScalableTargetAction, true), capacity(action.ScalableTargetAction, false), nextRun,
+				)
+			}
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+func capacity(action *applicationautoscaling.ScalableTargetAction, min bool) string {
+	if action == nil {
+		return "-"
+	}
+	value := action.MaxCapacity
+	if min {
+		value = action.MinCapacity
+	}
+	if value == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d", *value)
+}
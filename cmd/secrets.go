@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/fatih/color"
+	"github.com/flou/ecs/pkg/aws"
+	"github.com/spf13/cobra"
+)
+
+type secretsOpts struct {
+	region        string
+	cluster       string
+	serviceFilter string
+	output        string
+}
+
+func buildSecretsCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "secrets",
+		Short: "Manage the secrets referenced by your ECS services",
+	}
+
+	cmd.AddCommand(
+		buildSecretsCheckCmd(),
+	)
+	return cmd
+}
+
+func buildSecretsCheckCmd() *cobra.Command {
+	var opts = secretsOpts{}
+	var cmd = &cobra.Command{
+		Use:   "check",
+		Short: "Check that the secrets referenced by the services exist and are readable by their execution role",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandSecretsCheck(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVarP(&opts.cluster, "cluster", "c", "", "Name of the ECS cluster")
+	cmd.MarkFlagRequired("cluster")
+	cmd.Flags().StringVarP(&opts.serviceFilter, "service", "s", "", "Filter by the name of the ECS service")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "text", "Output format (text or json)")
+
+	return cmd
+}
+
+func runCommandSecretsCheck(options secretsOpts) error {
+	if options.output != "text" && options.output != "json" {
+		fmt.Printf("Invalid output format %s, expected text or json\n", options.output)
+		os.Exit(1)
+	}
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+	checker := aws.NewSecretChecker(client)
+
+	references := make([]aws.SecretReference, 0)
+	for _, service := range aws.ListServices(client, options.cluster, options.serviceFilter, "") {
+		taskDefinition := aws.ServiceTaskDefinition(client, *service.TaskDefinition)
+		references = append(references, checker.Check(options.cluster, *service.ServiceName, &taskDefinition)...)
+	}
+
+	problems := 0
+	for _, reference := range references {
+		if reference.Status != aws.SecretOK {
+			problems++
+		}
+	}
+
+	if options.output == "json" {
+		content, err := json.MarshalIndent(references, "", "  ")
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		fmt.Println(string(content))
+	} else {
+		for _, reference := range references {
+			status := color.GreenString("[%s]", reference.Status)
+			if reference.Status != aws.SecretOK {
+				status = color.RedString("[%s]", reference.Status)
+			}
+			fmt.Printf(
+				"%-9s %s container %s: %s -> %s\n",
+				status, reference.Service, reference.Container, reference.Name, reference.ValueFrom,
+			)
+			if reference.Message != "" {
+				fmt.Printf("          %s\n", reference.Message)
+			}
+		}
+		fmt.Printf("%d secret references checked, %d problems\n", len(references), problems)
+	}
+	if problems > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	"github.com/flou/ecs/pkg/aws"
@@ -15,6 +16,8 @@ type servicesOpts struct {
 	serviceType   string
 	printAll      bool
 	longOutput    bool
+	showSecrets   bool
+	secretPattern []string
 }
 
 func buildServicesCmd() *cobra.Command {
@@ -33,11 +36,17 @@ func buildServicesCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&opts.serviceType, "type", "t", "", "Filter by service launch type")
 	cmd.Flags().BoolVarP(&opts.printAll, "all", "a", false, "Print all services, ignoring their status")
 	cmd.Flags().BoolVarP(&opts.longOutput, "long", "l", false, "Enable detailed output of containers parameters")
+	cmd.Flags().BoolVar(&opts.showSecrets, "show-secrets", false, "Print the values of environment variables that look like secrets")
+	cmd.Flags().StringSliceVar(&opts.secretPattern, "secret-pattern", []string{}, "Additional regular expressions matching the names of secret environment variables")
 
 	return cmd
 }
 
 func runCommandServices(options servicesOpts) error {
+	if err := aws.AddSecretPatterns(options.secretPattern); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
 	cfg := aws.LoadAWSConfig(options.region)
 	client := ecs.New(cfg)
 
@@ -66,7 +75,7 @@ func runCommandServices(options servicesOpts) error {
 		if len(services) != 0 {
 			fmt.Printf(headerLine)
 			for _, svc := range services {
-				aws.PrintServiceDetails(client, &svc, options.longOutput)
+				aws.PrintServiceDetails(client, &svc, options.longOutput, options.showSecrets)
 			}
 		}
 		fmt.Println()
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/fatih/color"
+	"github.com/flou/ecs/pkg/aws"
+	"github.com/spf13/cobra"
+)
+
+type simulateOpts struct {
+	region         string
+	cluster        string
+	service        string
+	taskDefinition string
+	count          int
+}
+
+func buildSimulatePlacementCmd() *cobra.Command {
+	var opts = simulateOpts{}
+	var cmd = &cobra.Command{
+		Use:   "simulate-placement",
+		Short: "Simulate the placement of tasks on the container instances of an ECS cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandSimulatePlacement(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVarP(&opts.cluster, "cluster", "c", "", "Name of the ECS cluster")
+	cmd.MarkFlagRequired("cluster")
+	cmd.Flags().StringVarP(&opts.service, "service", "s", "", "Name of the ECS service whose placement constraints and strategies are applied")
+	cmd.Flags().StringVar(&opts.taskDefinition, "task-definition", "", "Family and revision of the task definition (defaults to the one of the service)")
+	cmd.Flags().IntVar(&opts.count, "count", 1, "Number of tasks to place")
+
+	return cmd
+}
+
+func runCommandSimulatePlacement(options simulateOpts) error {
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+
+	var placement aws.Placement
+	groupTasks := make(map[string]int64)
+	if options.service != "" {
+		ecsService, err := aws.FindService(client, options.cluster, options.service)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		placement.Constraints = ecsService.PlacementConstraints
+		placement.Strategies = ecsService.PlacementStrategy
+		placement.Group = "service:" + *ecsService.ServiceName
+		if options.taskDefinition == "" {
+			options.taskDefinition = *ecsService.TaskDefinition
+		}
+		for _, task := range aws.ListTasks(client, options.cluster, aws.TaskFilter{Service: options.service}) {
+			if task.ContainerInstanceArn != nil {
+				groupTasks[*task.ContainerInstanceArn]++
+			}
+		}
+	}
+	if options.taskDefinition == "" {
+		fmt.Println("Either --task-definition or --service is required")
+		os.Exit(1)
+	}
+
+	taskDefinition := aws.ServiceTaskDefinition(client, options.taskDefinition)
+	placement.CPU, placement.Memory = aws.TaskDefinitionResources(&taskDefinition)
+	placement.HostPorts = aws.TaskDefinitionHostPorts(&taskDefinition)
+	for _, constraint := range taskDefinition.PlacementConstraints {
+		placement.Constraints = append(placement.Constraints, ecs.PlacementConstraint{
+			Type:       ecs.PlacementConstraintType(constraint.Type),
+			Expression: constraint.Expression,
+		})
+	}
+
+	// task:group expressions need the groups of all the tasks running in the cluster
+	taskGroups := make(map[string][]string)
+	for _, constraint := range placement.Constraints {
+		if constraint.Expression != nil && strings.Contains(*constraint.Expression, "task:group") {
+			for _, task := range aws.ListTasks(client, options.cluster, aws.TaskFilter{Status: "RUNNING"}) {
+				if task.ContainerInstanceArn != nil && task.Group != nil {
+					taskGroups[*task.ContainerInstanceArn] = append(taskGroups[*task.ContainerInstanceArn], *task.Group)
+				}
+			}
+			break
+		}
+	}
+
+	instances := make([]*aws.PlacementInstance, 0)
+	for _, cinst := range aws.ListContainerInstances(client, options.cluster) {
+		instance := aws.NewPlacementInstance(&cinst)
+		instance.GroupTasks = groupTasks[*cinst.ContainerInstanceArn]
+		instance.TaskGroups = taskGroups[*cinst.ContainerInstanceArn]
+		instances = append(instances, instance)
+	}
+
+	fmt.Printf(
+		"Placing %d tasks of %s:%d (CPU %d / MEM %d) in cluster %s\n",
+		options.count, *taskDefinition.Family, *taskDefinition.Revision,
+		placement.CPU, placement.Memory, options.cluster,
+	)
+	fmt.Printf("Constraints: %s\n", describeConstraints(placement.Constraints))
+	fmt.Printf("Strategy:    %s\n\n", describeStrategies(placement.Strategies))
+
+	placed, reasons, err := placement.Simulate(instances, options.count)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Printf(
+		"%-20s  %-11s  %-11s  %8s  %9s  %9s\n",
+		"INSTANCE ID", "AZ", "INST.TYPE", "NEW TASKS", "CPU:free", "MEM:free",
+	)
+	for _, instance := range instances {
+		if instance.Placed == 0 {
+			continue
+		}
+		fmt.Printf(
+			"%-20s  %-11s  %-11s  %9d  %9d  %9d\n",
+			instance.InstanceID, instance.AvailabilityZone(), instance.InstanceType,
+			instance.Placed, instance.RemainingCPU, instance.RemainingMemory,
+		)
+	}
+	fmt.Println()
+
+	if placed < options.count {
+		fmt.Println(color.RedString("Only %d/%d tasks can be placed, task %d cannot be placed:", placed, options.count, placed+1))
+		for _, reason := range reasons {
+			fmt.Printf(" - %s\n", reason)
+		}
+		os.Exit(1)
+	}
+	fmt.Println(color.GreenString("All %d tasks can be placed", options.count))
+	return nil
+}
+
+func describeConstraints(constraints []ecs.PlacementConstraint) string {
+	descriptions := make([]string, 0)
+	for _, constraint := range constraints {
+		if constraint.Expression != nil {
+			descriptions = append(descriptions, fmt.Sprintf("%s(%s)", constraint.Type, *constraint.Expression))
+		} else {
+			descriptions = append(descriptions, string(constraint.Type))
+		}
+	}
+	if len(descriptions) == 0 {
+		return "none"
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+func describeStrategies(strategies []ecs.PlacementStrategy) string {
+	descriptions := make([]string, 0)
+	for _, strategy := range strategies {
+		if strategy.Field != nil {
+			descriptions = append(descriptions, fmt.Sprintf("%s(%s)", strategy.Type, *strategy.Field))
+		} else {
+			descriptions = append(descriptions, string(strategy.Type))
+		}
+	}
+	if len(descriptions) == 0 {
+		return "random"
+	}
+	return strings.Join(descriptions, ", ")
+}
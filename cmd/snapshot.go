@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/fatih/color"
+	"github.com/flou/ecs/pkg/aws"
+	"github.com/spf13/cobra"
+)
+
+type snapshotOpts struct {
+	region        string
+	clusterFilter string
+	output        string
+}
+
+func buildSnapshotCmd() *cobra.Command {
+	var opts = snapshotOpts{}
+	var cmd = &cobra.Command{
+		Use:   "snapshot",
+		Short: "Save the state of your ECS clusters to a JSON file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandSnapshot(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVarP(&opts.clusterFilter, "cluster", "c", "", "Filter by the name of the ECS cluster")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Path of the JSON file (standard output by default)")
+
+	return cmd
+}
+
+func buildDriftCmd() *cobra.Command {
+	var opts = snapshotOpts{}
+	var cmd = &cobra.Command{
+		Use:   "drift SNAPSHOT_FILE",
+		Short: "Compare your ECS clusters with a snapshot saved by `ecs snapshot`",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandDrift(opts, args[0])
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+
+	return cmd
+}
+
+func runCommandSnapshot(options snapshotOpts) error {
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+
+	snapshot := aws.TakeSnapshot(client, options.clusterFilter)
+	if options.output == "" {
+		content, err := json.MarshalIndent(snapshot, "", "  ")
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		fmt.Println(string(content))
+		return nil
+	}
+	if err := aws.WriteSnapshot(snapshot, options.output); err != nil {
+		fmt.Println("Failed to write snapshot: " + err.Error())
+		os.Exit(1)
+	}
+	services := 0
+	for _, cluster := range snapshot.Clusters {
+		services += len(cluster.Services)
+	}
+	fmt.Printf("Snapshot of %d clusters and %d services saved to %s\n", len(snapshot.Clusters), services, options.output)
+	return nil
+}
+
+func runCommandDrift(options snapshotOpts, path string) error {
+	saved, err := aws.ReadSnapshot(path)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	if options.region == "" {
+		options.region = saved.Region
+	}
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+
+	live := aws.TakeSnapshot(client, saved.ClusterFilter)
+	drifts := aws.CompareSnapshots(saved, live)
+	fmt.Printf("Comparing with snapshot of %s\n", saved.CreatedAt.Format("2006-01-02 15:04 MST"))
+	if len(drifts) > 0 {
+		printDrifts(drifts)
+		os.Exit(1)
+	}
+	fmt.Println("No drift detected")
+	return nil
+}
+
+// printDrifts prints the differences with a snapshot, grouped by cluster
+func printDrifts(drifts []aws.Drift) {
+	cluster := ""
+	for _, drift := range drifts {
+		if drift.Cluster != cluster {
+			cluster = drift.Cluster
+			fmt.Printf("--- CLUSTER: %s\n", cluster)
+		}
+		action := color.YellowString(drift.Action)
+		switch drift.Action {
+		case "+":
+			action = color.GreenString(drift.Action)
+		case "-":
+			action = color.RedString(drift.Action)
+		}
+		if drift.Details == "" {
+			fmt.Printf("%s %s\n", action, drift.Resource)
+			continue
+		}
+		fmt.Printf("%s %s: %s\n", action, drift.Resource, drift.Details)
+	}
+	fmt.Printf("\n%d differences found\n", len(drifts))
+}
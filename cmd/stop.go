@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/fatih/color"
+	"github.com/flou/ecs/pkg/aws"
+	"github.com/spf13/cobra"
+)
+
+type stopOpts struct {
+	region    string
+	cluster   string
+	service   string
+	tasks     []string
+	all       bool
+	unhealthy bool
+	olderThan string
+	reason    string
+	dryRun    bool
+	yes       bool
+	batch     int
+	pause     time.Duration
+	timeout   time.Duration
+}
+
+func buildStopCmd() *cobra.Command {
+	var opts = stopOpts{}
+	var cmd = &cobra.Command{
+		Use:   "stop",
+		Short: "Stop tasks running in an ECS cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandStop(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVarP(&opts.cluster, "cluster", "c", "", "Name of the ECS cluster")
+	cmd.MarkFlagRequired("cluster")
+	cmd.Flags().StringVarP(&opts.service, "service", "s", "", "Name of the ECS service whose tasks are stopped")
+	cmd.Flags().StringSliceVar(&opts.tasks, "task", []string{}, "ID or ARN of the tasks to stop")
+	cmd.Flags().BoolVarP(&opts.all, "all", "a", false, "Stop all the tasks of the service")
+	cmd.Flags().BoolVar(&opts.unhealthy, "unhealthy", false, "Stop the unhealthy tasks of the service")
+	cmd.Flags().StringVar(&opts.olderThan, "older-than", "", "Stop the tasks of the service started before this duration (e.g. 12h, 7d)")
+	cmd.Flags().StringVar(&opts.reason, "reason", "Stopped by ecs stop", "Reason displayed in the stopped task")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Only print the tasks that would be stopped")
+	cmd.Flags().BoolVarP(&opts.yes, "yes", "y", false, "Do not ask for confirmation")
+	cmd.Flags().IntVar(&opts.batch, "batch", 0, "Number of tasks stopped at once (0 to stop all the tasks at once)")
+	cmd.Flags().DurationVar(&opts.pause, "pause", 30*time.Second, "Pause between two batches of tasks, once the stopped tasks are replaced")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 10*time.Minute, "Maximum time to wait for the stopped tasks to be replaced before the next batch")
+
+	return cmd
+}
+
+func runCommandStop(options stopOpts) error {
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+
+	var tasks []ecs.Task
+	if len(options.tasks) > 0 {
+		tasks = aws.DescribeTasks(client, options.cluster, options.tasks)
+		if len(tasks) != len(options.tasks) {
+			fmt.Printf("Found %d tasks out of the %d given in cluster %s\n", len(tasks), len(options.tasks), options.cluster)
+			os.Exit(1)
+		}
+	} else {
+		if options.service == "" || (!options.all && !options.unhealthy && options.olderThan == "") {
+			fmt.Println("Either --task, or --service with one of --all, --unhealthy or --older-than is required")
+			os.Exit(1)
+		}
+		var olderThan time.Duration
+		if options.olderThan != "" {
+			duration, err := parseDuration(options.olderThan)
+			if err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+			olderThan = duration
+		}
+		serviceTasks := aws.ListTasks(client, options.cluster, aws.TaskFilter{Service: options.service})
+		for _, task := range serviceTasks {
+			if options.unhealthy && task.HealthStatus != ecs.HealthStatusUnhealthy {
+				continue
+			}
+			if olderThan > 0 && (task.StartedAt == nil || time.Since(*task.StartedAt) < olderThan) {
+				continue
+			}
+			tasks = append(tasks, task)
+		}
+		if len(tasks) > 0 && len(tasks) == len(serviceTasks) && options.batch == 0 {
+			fmt.Println(color.YellowString(
+				"WARNING: all the tasks of service %s will be stopped at once, use --batch to stop them in waves",
+				options.service,
+			))
+		}
+	}
+
+	if len(tasks) == 0 {
+		fmt.Println("No task to stop")
+		return nil
+	}
+	fmt.Printf("--- CLUSTER: %s (stopping %d tasks)\n", options.cluster, len(tasks))
+	printTasks(client, options.cluster, tasks, false, false)
+	fmt.Println()
+	if options.dryRun {
+		return nil
+	}
+	if !options.yes && !confirm(fmt.Sprintf("Stop %d tasks?", len(tasks))) {
+		return nil
+	}
+
+	batchSize := options.batch
+	if batchSize <= 0 {
+		batchSize = len(tasks)
+	}
+	failed := false
+	for index := 0; index < len(tasks); index += batchSize {
+		if index > 0 {
+			if err := waitForReplacement(client, options.cluster, tasks[index-batchSize:index], options.timeout); err != nil {
+				fmt.Println(color.RedString("%s, not stopping the next batches", err.Error()))
+				os.Exit(1)
+			}
+			fmt.Printf("Waiting %s before the next batch\n", options.pause)
+			time.Sleep(options.pause)
+		}
+		upperBound := index + batchSize
+		if upperBound > len(tasks) {
+			upperBound = len(tasks)
+		}
+		for _, task := range tasks[index:upperBound] {
+			taskID := aws.TaskIDFromArn(*task.TaskArn)
+			if err := aws.StopTask(client, options.cluster, *task.TaskArn, options.reason); err != nil {
+				fmt.Printf("%s Failed to stop task %s: %s\n", color.RedString("[KO]"), taskID, err.Error())
+				failed = true
+				continue
+			}
+			fmt.Printf("%s Stopped task %s\n", color.GreenString("[OK]"), taskID)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// waitForReplacement waits until the services of stopped tasks run their desired count of
+// tasks again, healthy when their containers define a healthcheck. It fails when the tasks are
+// not replaced before the timeout
+func waitForReplacement(client *ecs.Client, clusterName string, stopped []ecs.Task, timeout time.Duration) error {
+	services := make(map[string]bool)
+	for _, task := range stopped {
+		if task.Group != nil && strings.HasPrefix(*task.Group, "service:") {
+			services[strings.TrimPrefix(*task.Group, "service:")] = true
+		}
+	}
+	serviceNames := make([]string, 0, len(services))
+	for service := range services {
+		serviceNames = append(serviceNames, service)
+	}
+	sort.Strings(serviceNames)
+
+	since := time.Now()
+	for _, serviceName := range serviceNames {
+		service, err := aws.FindService(client, clusterName, serviceName)
+		if err != nil {
+			return err
+		}
+		healthCheck := false
+		for _, container := range aws.ServiceTaskDefinition(client, *service.TaskDefinition).ContainerDefinitions {
+			if container.HealthCheck != nil {
+				healthCheck = true
+			}
+		}
+		for {
+			ready := int64(0)
+			for _, task := range aws.ListTasks(client, clusterName, aws.TaskFilter{Service: serviceName, Status: "RUNNING"}) {
+				if *task.LastStatus == "RUNNING" && (!healthCheck || task.HealthStatus == ecs.HealthStatusHealthy) {
+					ready++
+				}
+			}
+			if ready >= *service.DesiredCount {
+				fmt.Printf("Service %s is running %d/%d tasks\n", color.GreenString(serviceName), ready, *service.DesiredCount)
+				break
+			}
+			if time.Since(since) > timeout {
+				return fmt.Errorf("Timed out after %s waiting for the tasks of service %s to be replaced", timeout, serviceName)
+			}
+			fmt.Printf("Waiting for service %s: %d/%d tasks running\n", color.YellowString(serviceName), ready, *service.DesiredCount)
+			time.Sleep(10 * time.Second)
+			if service, err = aws.FindService(client, clusterName, serviceName); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/fatih/color"
+	"github.com/flou/ecs/pkg/aws"
+	"github.com/spf13/cobra"
+)
+
+type fromComposeOpts struct {
+	region           string
+	family           string
+	networkMode      string
+	taskRoleArn      string
+	executionRoleArn string
+	register         bool
+}
+
+func buildTaskDefinitionsCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "taskdefs",
+		Short: "Manage ECS task definitions",
+	}
+
+	cmd.AddCommand(
+		buildFromComposeCmd(),
+	)
+	return cmd
+}
+
+func buildFromComposeCmd() *cobra.Command {
+	var opts = fromComposeOpts{}
+	var cmd = &cobra.Command{
+		Use:   "from-compose COMPOSE_FILE",
+		Short: "Convert a docker-compose file to an ECS task definition",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommandFromCompose(opts, args[0])
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
+	cmd.Flags().StringVar(&opts.family, "family", "", "Family of the task definition")
+	cmd.MarkFlagRequired("family")
+	cmd.Flags().StringVar(&opts.networkMode, "network-mode", "bridge", "Network mode of the task definition (bridge, host or awsvpc)")
+	cmd.Flags().StringVar(&opts.taskRoleArn, "task-role", "", "ARN of the IAM role of the task")
+	cmd.Flags().StringVar(&opts.executionRoleArn, "execution-role", "", "ARN of the IAM role used by ECS to pull images and send logs")
+	cmd.Flags().BoolVar(&opts.register, "register", false, "Register the task definition instead of printing it")
+
+	return cmd
+}
+
+func runCommandFromCompose(options fromComposeOpts, path string) error {
+	networkMode := ecs.NetworkMode(options.networkMode)
+	switch networkMode {
+	case ecs.NetworkModeBridge, ecs.NetworkModeHost, ecs.NetworkModeAwsvpc:
+	default:
+		fmt.Printf("Invalid network mode %s, expected bridge, host or awsvpc\n", options.networkMode)
+		os.Exit(1)
+	}
+
+	taskDefinition, warnings, err := aws.ComposeTaskDefinition(path, options.family, networkMode)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	if options.taskRoleArn != "" {
+		taskDefinition.TaskRoleArn = &options.taskRoleArn
+	}
+	if options.executionRoleArn != "" {
+		taskDefinition.ExecutionRoleArn = &options.executionRoleArn
+	}
+	// Warnings go to stderr to keep the task definition printed on stdout valid JSON
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, color.YellowString("WARNING: "+warning))
+	}
+
+	if !options.register {
+		output, err := aws.TaskDefinitionJSON(taskDefinition)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		fmt.Print(output)
+		return nil
+	}
+
+	cfg := aws.LoadAWSConfig(options.region)
+	client := ecs.New(cfg)
+	registered, err := aws.RegisterTaskDefinition(client, taskDefinition, nil)
+	if err != nil {
+		fmt.Println("Failed to register task definition: " + err.Error())
+		os.Exit(1)
+	}
+	fmt.Printf("Task definition %s:%d registered\n", color.YellowString(*registered.Family), *registered.Revision)
+	return nil
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	"github.com/flou/ecs/pkg/aws"
@@ -11,8 +13,10 @@ import (
 type tasksOpts struct {
 	region        string
 	clusterFilter string
-	serviceFilter string
 	longOutput    bool
+	showSecrets   bool
+	secretPattern []string
+	filter        aws.TaskFilter
 }
 
 func buildTasksCmd() *cobra.Command {
@@ -27,30 +31,63 @@ func buildTasksCmd() *cobra.Command {
 
 	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
 	cmd.Flags().StringVarP(&opts.clusterFilter, "cluster", "c", "", "Filter by the name of the ECS cluster")
-	cmd.Flags().StringVarP(&opts.serviceFilter, "service", "s", "", "Filter by the name of the ECS service")
+	cmd.Flags().StringVarP(&opts.filter.Service, "service", "s", "", "Filter by the name of the ECS service")
+	cmd.Flags().StringVarP(&opts.filter.LaunchType, "type", "t", "", "Filter by task launch type (fargate or ec2)")
+	cmd.Flags().StringVar(&opts.filter.Status, "status", "", "Filter by task status (RUNNING, PENDING or STOPPED)")
+	cmd.Flags().StringVar(&opts.filter.Family, "family", "", "Filter by the family of the task definition")
+	cmd.Flags().StringVar(&opts.filter.StartedBy, "started-by", "", "Filter by the startedBy value of the tasks")
+	cmd.Flags().StringVarP(&opts.filter.ContainerInstance, "instance", "i", "", "Filter by container instance (EC2 instance ID or container instance ARN)")
 	cmd.Flags().BoolVarP(&opts.longOutput, "long", "l", false, "Enable detailed output of containers parameters")
+	cmd.Flags().BoolVar(&opts.showSecrets, "show-secrets", false, "Print the values of environment variables that look like secrets")
+	cmd.Flags().StringSliceVar(&opts.secretPattern, "secret-pattern", []string{}, "Additional regular expressions matching the names of secret environment variables")
 
 	return cmd
 }
 
 func runCommandTasks(options tasksOpts) error {
+	switch strings.ToUpper(options.filter.Status) {
+	case "", "RUNNING", "PENDING", "STOPPED":
+	default:
+		fmt.Printf("Invalid task status %s, must be one of RUNNING, PENDING or STOPPED\n", options.filter.Status)
+		os.Exit(1)
+	}
+	if err := aws.AddSecretPatterns(options.secretPattern); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
 	cfg := aws.LoadAWSConfig(options.region)
 	client := ecs.New(cfg)
 
 	clusterNames := aws.ListClusters(client, options.clusterFilter)
 	for _, cluster := range aws.DescribeClusters(client, clusterNames) {
-		tasks := aws.ListTasks(client, *cluster.ClusterName, options.serviceFilter)
+		tasks := aws.ListTasks(client, *cluster.ClusterName, options.filter)
 		headerLine := fmt.Sprintf(
 			"--- CLUSTER: %s (%d tasks)\n", *cluster.ClusterName, len(tasks),
 		)
 
 		if len(tasks) != 0 {
 			fmt.Printf(headerLine)
-			for _, task := range tasks {
-				aws.PrintTaskDetails(client, &task, options.longOutput)
-			}
+			printTasks(client, *cluster.ClusterName, tasks, options.longOutput, options.showSecrets)
 		}
 		fmt.Println()
 	}
 	return nil
 }
+
+// printTasks prints a table of tasks running in an ECS cluster
+func printTasks(client *ecs.Client, clusterName string, tasks []ecs.Task, longOutput, showSecrets bool) {
+	instanceIds := aws.Ec2InstanceIds(client, clusterName)
+	fmt.Printf(
+		"%-36s  %-45s  %-10s  %-9s  %-15s  %-21s  %-11s  %-19s  %6s\n",
+		"TASK ID", "TASK DEFINITION", "STATUS", "HEALTH", "PRIVATE IP",
+		"ENI", "AZ", "INSTANCE/PLATFORM", "AGE",
+	)
+	for _, task := range tasks {
+		var instanceID string
+		if task.ContainerInstanceArn != nil {
+			instanceID = instanceIds[*task.ContainerInstanceArn]
+		}
+		aws.PrintTaskDetails(client, &task, instanceID, longOutput, showSecrets)
+	}
+}
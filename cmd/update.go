@@ -3,7 +3,11 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"math"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	"github.com/fatih/color"
@@ -12,11 +16,25 @@ import (
 )
 
 type updateOpts struct {
-	region       string
+	region        string
+	clusterFilter string
+	serviceFilter string
+	serviceType   string
+	desiredCount  int64
+	scale         string
+	force         bool
+	dryRun        bool
+	yes           bool
+	all           bool
+	parallel      int
+}
+
+// serviceUpdate is the update applied to one of the services matched by `ecs update`
+type serviceUpdate struct {
 	cluster      string
-	service      string
+	service      ecs.Service
 	desiredCount int64
-	force        bool
+	err          error
 }
 
 func buildUpdateCmd() *cobra.Command {
@@ -30,51 +48,180 @@ func buildUpdateCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&opts.region, "region", "r", "", "AWS region name")
-	cmd.Flags().StringVarP(&opts.cluster, "cluster", "c", "", "Name of the ECS cluster")
-	cmd.MarkFlagRequired("cluster")
-	cmd.Flags().StringVarP(&opts.service, "service", "s", "", "Name of the ECS service")
-	cmd.MarkFlagRequired("service")
+	cmd.Flags().StringVarP(&opts.clusterFilter, "cluster", "c", "", "Filter by the name of the ECS cluster")
+	cmd.Flags().StringVarP(&opts.serviceFilter, "service", "s", "", "Filter by the name of the ECS service")
+	cmd.Flags().StringVarP(&opts.serviceType, "type", "t", "", "Filter by service launch type")
 
 	cmd.Flags().Int64Var(&opts.desiredCount, "count", -1, "New DesiredCount")
+	cmd.Flags().StringVar(&opts.scale, "scale", "", "Multiply the DesiredCount of the services by a factor (e.g. 2x, 0.5x)")
 	cmd.Flags().BoolVarP(&opts.force, "force", "f", false, "Force a new deployment of the service")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Only print the services that would be updated")
+	cmd.Flags().BoolVarP(&opts.yes, "yes", "y", false, "Do not ask for confirmation")
+	cmd.Flags().BoolVar(&opts.all, "all", false, "Update all the services of all the clusters when no filter is given")
+	cmd.Flags().IntVar(&opts.parallel, "parallel", 5, "Number of services updated at once")
 
 	return cmd
 }
 
+// parseScaleFactor parses a scale factor like 2x or 0.5x
+func parseScaleFactor(scale string) (float64, error) {
+	factor, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(scale), "x"), 64)
+	if err != nil || factor < 0 {
+		return 0, fmt.Errorf("invalid scale factor %s, expected a positive number like 2x or 0.5x", scale)
+	}
+	return factor, nil
+}
+
 func runCommandUpdate(options updateOpts) error {
 	cfg := aws.LoadAWSConfig(options.region)
 	client := ecs.New(cfg)
 
-	ecsService, err := aws.FindService(client, options.cluster, options.service)
-	if err != nil {
-		fmt.Println(err.Error())
+	if options.clusterFilter == "" && options.serviceFilter == "" && options.serviceType == "" && !options.all {
+		fmt.Println("At least one of --cluster, --service or --type is required, use --all to update all the services")
+		os.Exit(1)
+	}
+	if options.desiredCount >= 0 && options.scale != "" {
+		fmt.Println("--count and --scale cannot be used together")
+		os.Exit(1)
+	}
+	if options.desiredCount < 0 && options.scale == "" && !options.force {
+		fmt.Println("One of --count, --scale or --force is required")
 		os.Exit(1)
 	}
-	params := ecs.UpdateServiceInput{
-		Cluster:            &options.cluster,
-		Service:            &options.service,
-		ForceNewDeployment: &options.force,
+	factor := 1.0
+	if options.scale != "" {
+		var err error
+		if factor, err = parseScaleFactor(options.scale); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+	}
+
+	updates := make([]serviceUpdate, 0)
+	exactMatch := false
+	clusterNames := aws.ListClusters(client, options.clusterFilter)
+	for _, cluster := range aws.DescribeClusters(client, clusterNames) {
+		for _, service := range aws.ListServices(client, *cluster.ClusterName, options.serviceFilter, options.serviceType) {
+			// ListServices matches the ARNs, which also contain the name of the cluster
+			if !strings.Contains(*service.ServiceName, options.serviceFilter) {
+				continue
+			}
+			if options.serviceFilter != "" && *service.ServiceName == options.serviceFilter {
+				exactMatch = true
+			}
+			update := serviceUpdate{cluster: *cluster.ClusterName, service: service, desiredCount: *service.DesiredCount}
+			if options.desiredCount >= 0 {
+				update.desiredCount = options.desiredCount
+			} else if options.scale != "" {
+				update.desiredCount = int64(math.Round(float64(*service.DesiredCount) * factor))
+			}
+			updates = append(updates, update)
+		}
+	}
+	// A service named exactly like the filter is not updated along with the ones containing its name
+	if exactMatch {
+		exact := make([]serviceUpdate, 0)
+		for _, update := range updates {
+			if *update.service.ServiceName == options.serviceFilter {
+				exact = append(exact, update)
+			}
+		}
+		updates = exact
+	}
+	if len(updates) == 0 {
+		fmt.Println("No service matches the filters")
+		os.Exit(1)
 	}
 
-	if options.desiredCount >= 0 {
-		if *ecsService.DesiredCount == options.desiredCount {
-			fmt.Printf("Service %s already has a DesiredCount of %d\n",
-				color.YellowString(options.service), options.desiredCount,
-			)
+	if !options.force {
+		pending := make([]serviceUpdate, 0)
+		for _, update := range updates {
+			if update.desiredCount == *update.service.DesiredCount {
+				fmt.Printf("Service %s already has a DesiredCount of %d\n",
+					color.YellowString(*update.service.ServiceName), update.desiredCount,
+				)
+				continue
+			}
+			pending = append(pending, update)
+		}
+		updates = pending
+		if len(updates) == 0 {
 			return nil
 		}
+	}
+
+	fmt.Printf("%-30s  %-50s  %-15s  %7s\n", "CLUSTER", "SERVICE", "DESIRED", "RUNNING")
+	for _, update := range updates {
 		fmt.Printf(
-			"Updating %s / DesiredCount[%d -> %d] RunningCount={%d}\n",
-			color.YellowString(options.service), *ecsService.DesiredCount, options.desiredCount, *ecsService.RunningCount,
+			"%-30s  %-50s  %-15s  %7d\n",
+			update.cluster, *update.service.ServiceName,
+			fmt.Sprintf("%d -> %d", *update.service.DesiredCount, update.desiredCount),
+			*update.service.RunningCount,
 		)
-		params.DesiredCount = &options.desiredCount
 	}
+	fmt.Println()
+	for _, update := range updates {
+		target := aws.ScalableTarget(client, update.cluster, *update.service.ServiceName)
+		if target != nil && (update.desiredCount < *target.MinCapacity || update.desiredCount > *target.MaxCapacity) {
+			fmt.Println(color.YellowString(
+				"WARNING: DesiredCount %d of service %s is outside its Auto Scaling range (min %d / max %d), it will be reverted by Application Auto Scaling",
+				update.desiredCount, *update.service.ServiceName, *target.MinCapacity, *target.MaxCapacity,
+			))
+		}
+	}
+	if options.dryRun {
+		return nil
+	}
+	if !options.yes {
+		if !stdinIsTerminal() {
+			fmt.Println("Standard input is not a terminal, use --yes to update the services without confirmation")
+			os.Exit(1)
+		}
+		if !confirm(fmt.Sprintf("Update %d services?", len(updates))) {
+			fmt.Println("Aborted")
+			os.Exit(1)
+		}
+	}
+
+	parallel := options.parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+	semaphore := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for index := range updates {
+		wg.Add(1)
+		go func(update *serviceUpdate) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			params := ecs.UpdateServiceInput{
+				Cluster:            &update.cluster,
+				Service:            update.service.ServiceName,
+				ForceNewDeployment: &options.force,
+			}
+			if options.desiredCount >= 0 || options.scale != "" {
+				params.DesiredCount = &update.desiredCount
+			}
+			_, update.err = client.UpdateServiceRequest(&params).Send(context.Background())
+		}(&updates[index])
+	}
+	wg.Wait()
 
-	_, err = client.UpdateServiceRequest(&params).Send(context.Background())
-	if err != nil {
-		fmt.Println(err.Error())
+	failed := false
+	for _, update := range updates {
+		if update.err != nil {
+			fmt.Printf("%s Failed to update service %s: %s\n", color.RedString("[KO]"), *update.service.ServiceName, update.err.Error())
+			failed = true
+			continue
+		}
+		fmt.Printf(
+			"%s Service %s successfully updated: DesiredCount=%d\n",
+			color.GreenString("[OK]"), color.YellowString(*update.service.ServiceName), update.desiredCount,
+		)
+	}
+	if failed {
 		os.Exit(1)
 	}
-	fmt.Printf("Service %s successfully updated: DesiredCount=%d\n", color.YellowString(options.service), options.desiredCount)
 	return nil
 }
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// confirm asks the user a yes/no question on the terminal, defaulting to no
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// stdinIsTerminal tells whether the standard input is a terminal on which confirm can ask questions
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// parseDuration parses a duration like time.ParseDuration, with support for days (e.g. 7d)
+func parseDuration(duration string) (time.Duration, error) {
+	if strings.HasSuffix(duration, "d") {
+		days, err := strconv.ParseFloat(strings.TrimSuffix(duration, "d"), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %s", duration)
+		}
+		return time.Duration(days * 24 * float64(time.Hour)), nil
+	}
+	return time.ParseDuration(duration)
+}
+
+// compareVersions compares two dotted version numbers (e.g. 1.45.0 or 19.03.13-ce), returning
+// -1, 0 or 1 if v1 is respectively lower than, equal to or greater than v2
+func compareVersions(v1, v2 string) int {
+	parts1 := strings.Split(strings.SplitN(strings.TrimPrefix(v1, "v"), "-", 2)[0], ".")
+	parts2 := strings.Split(strings.SplitN(strings.TrimPrefix(v2, "v"), "-", 2)[0], ".")
+	for i := 0; i < len(parts1) || i < len(parts2); i++ {
+		var n1, n2 int
+		if i < len(parts1) {
+			n1, _ = strconv.Atoi(parts1[i])
+		}
+		if i < len(parts2) {
+			n2, _ = strconv.Atoi(parts2[i])
+		}
+		if n1 < n2 {
+			return -1
+		}
+		if n1 > n2 {
+			return 1
+		}
+	}
+	return 0
+}
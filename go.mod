@@ -7,4 +7,5 @@ require (
 	github.com/aws/aws-sdk-go-v2 v0.24.0
 	github.com/fatih/color v1.9.0
 	github.com/spf13/cobra v1.0.0
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,24 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// DefaultAMIParameter is the SSM parameter holding the recommended ECS-optimized Amazon Linux 2 AMI
+const DefaultAMIParameter = "/aws/service/ecs/optimized-ami/amazon-linux-2/recommended/image_id"
+
+// RecommendedAMI returns the ID of the recommended ECS-optimized AMI published in an SSM parameter
+func RecommendedAMI(client *ecs.Client, parameter string) string {
+	ssmClient := ssm.New(client.Config)
+	resp, err := ssmClient.GetParameterRequest(&ssm.GetParameterInput{Name: &parameter}).Send(context.Background())
+	if err != nil {
+		fmt.Println("Failed to get the recommended ECS-optimized AMI: " + err.Error())
+		os.Exit(1)
+	}
+	return *resp.Parameter.Value
+}
@@ -0,0 +1,126 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/applicationautoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/fatih/color"
+)
+
+// serviceResourceID returns the Application Auto Scaling resource ID of an ECS service
+func serviceResourceID(clusterName, serviceName string) string {
+	return fmt.Sprintf("service/%s/%s", clusterName, serviceName)
+}
+
+// ScalableTarget returns the Application Auto Scaling target of an ECS service,
+// or nil if the service does not use Service Auto Scaling
+func ScalableTarget(client *ecs.Client, clusterName, serviceName string) *applicationautoscaling.ScalableTarget {
+	aasClient := applicationautoscaling.New(client.Config)
+	resp, err := aasClient.DescribeScalableTargetsRequest(&applicationautoscaling.DescribeScalableTargetsInput{
+		ServiceNamespace:  applicationautoscaling.ServiceNamespaceEcs,
+		ScalableDimension: applicationautoscaling.ScalableDimensionEcsServiceDesiredCount,
+		ResourceIds:       []string{serviceResourceID(clusterName, serviceName)},
+	}).Send(context.Background())
+	if err != nil {
+		fmt.Println("Failed to describe scalable targets: " + err.Error())
+		os.Exit(1)
+	}
+	if len(resp.ScalableTargets) == 0 {
+		return nil
+	}
+	return &resp.ScalableTargets[0]
+}
+
+// ScalingPolicies returns the Application Auto Scaling policies attached to an ECS service
+func ScalingPolicies(client *ecs.Client, clusterName, serviceName string) []applicationautoscaling.ScalingPolicy {
+	aasClient := applicationautoscaling.New(client.Config)
+	resourceID := serviceResourceID(clusterName, serviceName)
+	req := aasClient.DescribeScalingPoliciesRequest(&applicationautoscaling.DescribeScalingPoliciesInput{
+		ServiceNamespace:  applicationautoscaling.ServiceNamespaceEcs,
+		ScalableDimension: applicationautoscaling.ScalableDimensionEcsServiceDesiredCount,
+		ResourceId:        &resourceID,
+	})
+	p := applicationautoscaling.NewDescribeScalingPoliciesPaginator(req)
+
+	policies := make([]applicationautoscaling.ScalingPolicy, 0)
+	for p.Next(context.Background()) {
+		policies = append(policies, p.CurrentPage().ScalingPolicies...)
+	}
+	if err := p.Err(); err != nil {
+		fmt.Println("Failed to describe scaling policies: " + err.Error())
+		os.Exit(1)
+	}
+	return policies
+}
+
+// RegisterScalableTarget creates or updates the Application Auto Scaling target of an ECS service,
+// nil parameters being left unchanged
+func RegisterScalableTarget(client *ecs.Client, clusterName, serviceName string, minCapacity, maxCapacity *int64, suspendedState *applicationautoscaling.SuspendedState) {
+	aasClient := applicationautoscaling.New(client.Config)
+	resourceID := serviceResourceID(clusterName, serviceName)
+	_, err := aasClient.RegisterScalableTargetRequest(&applicationautoscaling.RegisterScalableTargetInput{
+		ServiceNamespace:  applicationautoscaling.ServiceNamespaceEcs,
+		ScalableDimension: applicationautoscaling.ScalableDimensionEcsServiceDesiredCount,
+		ResourceId:        &resourceID,
+		MinCapacity:       minCapacity,
+		MaxCapacity:       maxCapacity,
+		SuspendedState:    suspendedState,
+	}).Send(context.Background())
+	if err != nil {
+		fmt.Println("Failed to register scalable target: " + err.Error())
+		os.Exit(1)
+	}
+}
+
+// SuspendScaling suspends or resumes the dynamic and scheduled scaling activities of an ECS service
+func SuspendScaling(client *ecs.Client, clusterName, serviceName string, suspend bool) {
+	RegisterScalableTarget(client, clusterName, serviceName, nil, nil, &applicationautoscaling.SuspendedState{
+		DynamicScalingInSuspended:  &suspend,
+		DynamicScalingOutSuspended: &suspend,
+		ScheduledScalingSuspended:  &suspend,
+	})
+}
+
+// ScalingSuspended tells whether some scaling activities of a scalable target are suspended
+func ScalingSuspended(target *applicationautoscaling.ScalableTarget) bool {
+	state := target.SuspendedState
+	return state != nil && ((state.DynamicScalingInSuspended != nil && *state.DynamicScalingInSuspended) ||
+		(state.DynamicScalingOutSuspended != nil && *state.DynamicScalingOutSuspended) ||
+		(state.ScheduledScalingSuspended != nil && *state.ScheduledScalingSuspended))
+}
+
+// printAutoScaling prints the Service Auto Scaling configuration of an ECS service
+func printAutoScaling(client *ecs.Client, clusterName, serviceName string) {
+	target := ScalableTarget(client, clusterName, serviceName)
+	if target == nil {
+		return
+	}
+	suspended := ""
+	if ScalingSuspended(target) {
+		suspended = color.YellowString(" (suspended)")
+	}
+	fmt.Printf("Auto Scaling: min %d / max %d%s\n", *target.MinCapacity, *target.MaxCapacity, suspended)
+	for _, policy := range ScalingPolicies(client, clusterName, serviceName) {
+		details := ""
+		if config := policy.TargetTrackingScalingPolicyConfiguration; config != nil {
+			metric := "-"
+			if config.PredefinedMetricSpecification != nil {
+				metric = string(config.PredefinedMetricSpecification.PredefinedMetricType)
+			} else if config.CustomizedMetricSpecification != nil {
+				metric = *config.CustomizedMetricSpecification.MetricName
+			}
+			details = fmt.Sprintf("%s = %.1f", metric, *config.TargetValue)
+		} else if policy.StepScalingPolicyConfiguration != nil {
+			alarms := make([]string, 0)
+			for _, alarm := range policy.Alarms {
+				alarms = append(alarms, *alarm.AlarmName)
+			}
+			details = "alarms: " + strings.Join(alarms, ", ")
+		}
+		fmt.Printf("  - %s (%s) %s\n", *policy.PolicyName, policy.PolicyType, details)
+	}
+}
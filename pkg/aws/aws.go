@@ -3,6 +3,7 @@ package aws
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 )
@@ -17,6 +18,32 @@ func clusterNameFromArn(clusterArn string) string {
 	return strings.Split(clusterArn, "/")[len(splitClusterArn)-1]
 }
 
+// AutoScalingGroupNameFromArn returns the name of an Auto Scaling group from its ARN
+func AutoScalingGroupNameFromArn(asgArn string) string {
+	splitAsgArn := strings.Split(asgArn, "/")
+	return splitAsgArn[len(splitAsgArn)-1]
+}
+
+// TaskIDFromArn returns the ID of a task from its ARN
+func TaskIDFromArn(taskArn string) string {
+	splitTaskArn := strings.Split(taskArn, "/")
+	return splitTaskArn[len(splitTaskArn)-1]
+}
+
+// Format a duration in a short human readable form, e.g. 3d4h, 5h12m or 12m
+func humanizeDuration(duration time.Duration) string {
+	days := int(duration.Hours()) / 24
+	hours := int(duration.Hours()) % 24
+	minutes := int(duration.Minutes()) % 60
+	if days > 0 {
+		return fmt.Sprintf("%dd%dh", days, hours)
+	}
+	if hours > 0 {
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
+
 // Split a list of strings into a list of smaller lists containing up to `count` items
 func chunk(list []string, count int) [][]string {
 	newList := make([][]string, len(list)/count+1)
@@ -31,6 +58,17 @@ func chunk(list []string, count int) [][]string {
 	return newList
 }
 
+// launchType converts a user-provided launch type ("fargate", "ec2") to its ECS value
+func launchType(serviceType string) ecs.LaunchType {
+	switch strings.ToLower(serviceType) {
+	case "fargate":
+		return ecs.LaunchTypeFargate
+	case "ec2":
+		return ecs.LaunchTypeEc2
+	}
+	return ""
+}
+
 func linkToIAM(roleArn string) string {
 	return fmt.Sprintf("https://console.aws.amazon.com/iam/home#/roles/%s", roleArn)
 }
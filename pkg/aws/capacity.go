@@ -0,0 +1,84 @@
+package aws
+
+import (
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+// InstanceResources holds the CPU and memory registered and remaining on a container instance
+type InstanceResources struct {
+	InstanceID       string
+	InstanceType     string
+	Active           bool
+	RegisteredCPU    int64
+	RemainingCPU     int64
+	RegisteredMemory int64
+	RemainingMemory  int64
+}
+
+// ContainerInstanceResources reads the CPU and memory resources of a container instance
+func ContainerInstanceResources(containerInstance *ecs.ContainerInstance) InstanceResources {
+	resources := InstanceResources{
+		InstanceID:       *containerInstance.Ec2InstanceId,
+		Active:           *containerInstance.Status == string(ecs.ContainerInstanceStatusActive),
+		RegisteredCPU:    resourceValue(containerInstance.RegisteredResources, "CPU"),
+		RemainingCPU:     resourceValue(containerInstance.RemainingResources, "CPU"),
+		RegisteredMemory: resourceValue(containerInstance.RegisteredResources, "MEMORY"),
+		RemainingMemory:  resourceValue(containerInstance.RemainingResources, "MEMORY"),
+	}
+	if instanceType := FindAttribute(containerInstance.Attributes, "ecs.instance-type").Value; instanceType != nil {
+		resources.InstanceType = *instanceType
+	}
+	return resources
+}
+
+func resourceValue(resources []ecs.Resource, name string) int64 {
+	resource := FindResource(resources, name)
+	if resource.IntegerValue == nil {
+		return 0
+	}
+	return *resource.IntegerValue
+}
+
+// Fits returns how many tasks requiring cpu and memory can still be placed on the instance
+func (r InstanceResources) Fits(cpu, memory int64) int64 {
+	if !r.Active || (cpu == 0 && memory == 0) {
+		return 0
+	}
+	count := int64(-1)
+	if cpu > 0 {
+		count = r.RemainingCPU / cpu
+	}
+	if memory > 0 && (count < 0 || r.RemainingMemory/memory < count) {
+		count = r.RemainingMemory / memory
+	}
+	return count
+}
+
+// TaskDefinitionResources returns the CPU and memory reserved by a task definition, either
+// at the task level or as the sum of the reservations of its containers
+func TaskDefinitionResources(taskDefinition *ecs.TaskDefinition) (int64, int64) {
+	var cpu, memory int64
+	for _, container := range taskDefinition.ContainerDefinitions {
+		if container.Cpu != nil {
+			cpu += *container.Cpu
+		}
+		if container.Memory != nil {
+			memory += *container.Memory
+		} else if container.MemoryReservation != nil {
+			memory += *container.MemoryReservation
+		}
+	}
+	if taskDefinition.Cpu != nil {
+		if value, err := strconv.ParseInt(*taskDefinition.Cpu, 10, 64); err == nil {
+			cpu = value
+		}
+	}
+	if taskDefinition.Memory != nil {
+		if value, err := strconv.ParseInt(*taskDefinition.Memory, 10, 64); err == nil {
+			memory = value
+		}
+	}
+	return cpu, memory
+}
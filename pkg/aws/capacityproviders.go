@@ -0,0 +1,125 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+// DescribeCapacityProviders describes the capacity providers with the given names
+func DescribeCapacityProviders(client *ecs.Client, names []string) []ecs.CapacityProvider {
+	capacityProviders := make([]ecs.CapacityProvider, 0)
+	for _, providers := range chunk(names, 10) {
+		if len(providers) == 0 {
+			continue
+		}
+		resp, err := client.DescribeCapacityProvidersRequest(
+			&ecs.DescribeCapacityProvidersInput{CapacityProviders: providers}).Send(context.Background())
+		if err != nil {
+			fmt.Println("Failed to describe capacity providers: " + err.Error())
+			os.Exit(1)
+		}
+		capacityProviders = append(capacityProviders, resp.CapacityProviders...)
+	}
+	sort.Slice(capacityProviders, func(i, j int) bool {
+		return *capacityProviders[i].Name < *capacityProviders[j].Name
+	})
+	return capacityProviders
+}
+
+// AutoScalingGroupProviders returns the names of the capacity providers of a cluster backed by
+// an Auto Scaling group, which place tasks on EC2 instances
+func AutoScalingGroupProviders(client *ecs.Client, cluster *ecs.Cluster) map[string]bool {
+	providers := make(map[string]bool)
+	for _, provider := range DescribeCapacityProviders(client, cluster.CapacityProviders) {
+		if provider.AutoScalingGroupProvider != nil {
+			providers[*provider.Name] = true
+		}
+	}
+	return providers
+}
+
+// ServiceUsesEC2 tells whether the tasks of a service run on EC2 instances, either with the EC2
+// launch type or with a capacity provider strategy including a provider backed by an Auto
+// Scaling group. Services with neither use the default strategy of their cluster
+func ServiceUsesEC2(service *ecs.Service, cluster *ecs.Cluster, asgProviders map[string]bool) bool {
+	if service.LaunchType != "" {
+		return service.LaunchType == ecs.LaunchTypeEc2
+	}
+	strategy := service.CapacityProviderStrategy
+	if len(strategy) == 0 {
+		strategy = cluster.DefaultCapacityProviderStrategy
+	}
+	for _, item := range strategy {
+		if asgProviders[*item.CapacityProvider] {
+			return true
+		}
+	}
+	return false
+}
+
+// CapacityProviderReservation returns the latest value of the CapacityProviderReservation metric
+// of a capacity provider, which is only published when managed scaling is enabled
+func CapacityProviderReservation(client *ecs.Client, clusterName, capacityProvider string) (float64, bool) {
+	cwClient := cloudwatch.New(client.Config)
+	var (
+		namespace   = "AWS/ECS/ManagedScaling"
+		metricName  = "CapacityProviderReservation"
+		period      = int64(60)
+		endTime     = time.Now()
+		startTime   = endTime.Add(-15 * time.Minute)
+		clusterDim  = "ClusterName"
+		providerDim = "CapacityProviderName"
+	)
+	resp, err := cwClient.GetMetricStatisticsRequest(&cloudwatch.GetMetricStatisticsInput{
+		Namespace:  &namespace,
+		MetricName: &metricName,
+		Dimensions: []cloudwatch.Dimension{
+			{Name: &clusterDim, Value: &clusterName},
+			{Name: &providerDim, Value: &capacityProvider},
+		},
+		StartTime:  &startTime,
+		EndTime:    &endTime,
+		Period:     &period,
+		Statistics: []cloudwatch.Statistic{cloudwatch.StatisticAverage},
+	}).Send(context.Background())
+	if err != nil {
+		fmt.Println("Failed to get CapacityProviderReservation metric: " + err.Error())
+		os.Exit(1)
+	}
+	if len(resp.Datapoints) == 0 {
+		return 0, false
+	}
+	latest := resp.Datapoints[0]
+	for _, datapoint := range resp.Datapoints {
+		if datapoint.Timestamp.After(*latest.Timestamp) {
+			latest = datapoint
+		}
+	}
+	return *latest.Average, true
+}
+
+// FormatCapacityProviderStrategy formats a capacity provider strategy on a single line
+func FormatCapacityProviderStrategy(strategy []ecs.CapacityProviderStrategyItem) string {
+	items := make([]string, 0)
+	for _, item := range strategy {
+		details := make([]string, 0)
+		if item.Base != nil && *item.Base > 0 {
+			details = append(details, fmt.Sprintf("base %d", *item.Base))
+		}
+		if item.Weight != nil {
+			details = append(details, fmt.Sprintf("weight %d", *item.Weight))
+		}
+		items = append(items, fmt.Sprintf("%s (%s)", *item.CapacityProvider, strings.Join(details, ", ")))
+	}
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,557 @@
+package aws
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"gopkg.in/yaml.v3"
+)
+
+// composeStrings is a docker-compose value given either as a string or as a list of strings
+type composeStrings struct {
+	values []string
+	shell  bool
+}
+
+func (s *composeStrings) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		s.values, s.shell = []string{node.Value}, true
+		return nil
+	}
+	return node.Decode(&s.values)
+}
+
+// composeMapping is a docker-compose value given either as a mapping or as a list of KEY=VALUE,
+// nil values being read from the environment by docker-compose
+type composeMapping map[string]*string
+
+func (m *composeMapping) UnmarshalYAML(node *yaml.Node) error {
+	*m = make(composeMapping)
+	if node.Kind == yaml.SequenceNode {
+		var items []string
+		if err := node.Decode(&items); err != nil {
+			return err
+		}
+		for _, item := range items {
+			parts := strings.SplitN(item, "=", 2)
+			if len(parts) == 1 {
+				(*m)[parts[0]] = nil
+				continue
+			}
+			value := parts[1]
+			(*m)[parts[0]] = &value
+		}
+		return nil
+	}
+	var values map[string]*string
+	if err := node.Decode(&values); err != nil {
+		return err
+	}
+	for key, value := range values {
+		(*m)[key] = value
+	}
+	return nil
+}
+
+type composeHealthcheck struct {
+	Test        composeStrings `yaml:"test"`
+	Interval    string         `yaml:"interval"`
+	Timeout     string         `yaml:"timeout"`
+	StartPeriod string         `yaml:"start_period"`
+	Retries     *int64         `yaml:"retries"`
+	Disable     bool           `yaml:"disable"`
+}
+
+type composeResources struct {
+	Cpus   string `yaml:"cpus"`
+	Memory string `yaml:"memory"`
+}
+
+type composeDeploy struct {
+	Replicas  *int64 `yaml:"replicas"`
+	Resources struct {
+		Limits       composeResources `yaml:"limits"`
+		Reservations composeResources `yaml:"reservations"`
+	} `yaml:"resources"`
+}
+
+type composeLogging struct {
+	Driver  string            `yaml:"driver"`
+	Options map[string]string `yaml:"options"`
+}
+
+type composeService struct {
+	Image           string              `yaml:"image"`
+	Command         composeStrings      `yaml:"command"`
+	Entrypoint      composeStrings      `yaml:"entrypoint"`
+	Environment     composeMapping      `yaml:"environment"`
+	EnvFile         composeStrings      `yaml:"env_file"`
+	Ports           []yaml.Node         `yaml:"ports"`
+	Healthcheck     *composeHealthcheck `yaml:"healthcheck"`
+	Deploy          composeDeploy       `yaml:"deploy"`
+	MemLimit        string              `yaml:"mem_limit"`
+	MemReservation  string              `yaml:"mem_reservation"`
+	Cpus            string              `yaml:"cpus"`
+	CPUShares       *int64              `yaml:"cpu_shares"`
+	Logging         *composeLogging     `yaml:"logging"`
+	WorkingDir      string              `yaml:"working_dir"`
+	User            string              `yaml:"user"`
+	Hostname        string              `yaml:"hostname"`
+	DependsOn       yaml.Node           `yaml:"depends_on"`
+	Labels          composeMapping      `yaml:"labels"`
+	Links           []string            `yaml:"links"`
+	Privileged      *bool               `yaml:"privileged"`
+	ReadOnly        *bool               `yaml:"read_only"`
+	StopGracePeriod string              `yaml:"stop_grace_period"`
+}
+
+// composeSupportedKeys lists the keys of a docker-compose service converted to a container definition
+var composeSupportedKeys = map[string]bool{
+	"image": true, "command": true, "entrypoint": true, "environment": true, "env_file": true,
+	"ports": true, "healthcheck": true, "deploy": true, "mem_limit": true, "mem_reservation": true,
+	"cpus": true, "cpu_shares": true, "logging": true, "working_dir": true, "user": true,
+	"hostname": true, "depends_on": true, "labels": true, "links": true, "privileged": true,
+	"read_only": true, "stop_grace_period": true,
+}
+
+// ComposeTaskDefinition converts the services of a docker-compose file to the containers of a
+// task definition. It also returns warnings about the settings that could not be converted
+func ComposeTaskDefinition(path, family string, networkMode ecs.NetworkMode) (ecs.TaskDefinition, []string, error) {
+	taskDefinition := ecs.TaskDefinition{Family: &family, NetworkMode: networkMode}
+	warnings := make([]string, 0)
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return taskDefinition, warnings, err
+	}
+	var compose struct {
+		Services map[string]yaml.Node `yaml:"services"`
+		Volumes  yaml.Node            `yaml:"volumes"`
+		Networks yaml.Node            `yaml:"networks"`
+		Secrets  yaml.Node            `yaml:"secrets"`
+		Configs  yaml.Node            `yaml:"configs"`
+	}
+	if err := yaml.Unmarshal(content, &compose); err != nil {
+		return taskDefinition, warnings, fmt.Errorf("invalid docker-compose file %s: %s", path, err.Error())
+	}
+	if len(compose.Services) == 0 {
+		return taskDefinition, warnings, fmt.Errorf("no service found in docker-compose file %s", path)
+	}
+	for _, topLevel := range []struct {
+		key  string
+		node yaml.Node
+	}{
+		{"configs", compose.Configs}, {"networks", compose.Networks}, {"secrets", compose.Secrets}, {"volumes", compose.Volumes},
+	} {
+		if topLevel.node.Kind != 0 {
+			warnings = append(warnings, fmt.Sprintf("top-level %s are not supported", topLevel.key))
+		}
+	}
+
+	names := make([]string, 0, len(compose.Services))
+	for name := range compose.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		node := compose.Services[name]
+		var keys map[string]yaml.Node
+		if err := node.Decode(&keys); err != nil {
+			return taskDefinition, warnings, fmt.Errorf("invalid service %s: %s", name, err.Error())
+		}
+		unsupported := make([]string, 0)
+		for key := range keys {
+			if !composeSupportedKeys[key] {
+				unsupported = append(unsupported, key)
+			}
+		}
+		sort.Strings(unsupported)
+		for _, key := range unsupported {
+			warnings = append(warnings, fmt.Sprintf("service %s: %s is not supported", name, key))
+		}
+
+		var service composeService
+		if err := node.Decode(&service); err != nil {
+			return taskDefinition, warnings, fmt.Errorf("invalid service %s: %s", name, err.Error())
+		}
+		container, containerWarnings, err := composeContainer(name, service, filepath.Dir(path), networkMode)
+		if err != nil {
+			return taskDefinition, warnings, fmt.Errorf("service %s: %s", name, err.Error())
+		}
+		for _, warning := range containerWarnings {
+			warnings = append(warnings, fmt.Sprintf("service %s: %s", name, warning))
+		}
+		taskDefinition.ContainerDefinitions = append(taskDefinition.ContainerDefinitions, container)
+	}
+	return taskDefinition, warnings, nil
+}
+
+func composeContainer(name string, service composeService, directory string, networkMode ecs.NetworkMode) (ecs.ContainerDefinition, []string, error) {
+	essential := true
+	container := ecs.ContainerDefinition{Name: &name, Essential: &essential}
+	warnings := make([]string, 0)
+
+	if service.Image == "" {
+		return container, warnings, fmt.Errorf("an image is required, build is not supported")
+	}
+	container.Image = &service.Image
+
+	var err error
+	if container.Command, err = composeCommand(service.Command); err != nil {
+		return container, warnings, err
+	}
+	if container.EntryPoint, err = composeCommand(service.Entrypoint); err != nil {
+		return container, warnings, err
+	}
+	if service.WorkingDir != "" {
+		container.WorkingDirectory = &service.WorkingDir
+	}
+	if service.User != "" {
+		container.User = &service.User
+	}
+	if service.Hostname != "" {
+		if networkMode == ecs.NetworkModeAwsvpc {
+			warnings = append(warnings, "hostname is not supported with the awsvpc network mode")
+		} else {
+			container.Hostname = &service.Hostname
+		}
+	}
+	container.Links = service.Links
+	container.Privileged = service.Privileged
+	container.ReadonlyRootFilesystem = service.ReadOnly
+	if service.StopGracePeriod != "" {
+		duration, err := time.ParseDuration(service.StopGracePeriod)
+		if err != nil {
+			return container, warnings, fmt.Errorf("invalid stop_grace_period %s", service.StopGracePeriod)
+		}
+		seconds := int64(duration.Seconds())
+		container.StopTimeout = &seconds
+	}
+
+	// Variables of env_file are overridden by the ones of environment, like docker-compose does
+	environment := make(map[string]string)
+	for _, envFile := range service.EnvFile.values {
+		if !filepath.IsAbs(envFile) {
+			envFile = filepath.Join(directory, envFile)
+		}
+		if err := readEnvFile(envFile, environment); err != nil {
+			return container, warnings, err
+		}
+	}
+	for key, value := range service.Environment {
+		if value == nil {
+			warnings = append(warnings, fmt.Sprintf("environment variable %s has no value and is ignored", key))
+			continue
+		}
+		environment[key] = *value
+	}
+	if len(environment) > 0 {
+		SetContainerEnvironment(&container, environment)
+	}
+
+	if len(service.Labels) > 0 {
+		container.DockerLabels = make(map[string]string)
+		for key, value := range service.Labels {
+			if value != nil {
+				container.DockerLabels[key] = *value
+			}
+		}
+	}
+
+	for _, port := range service.Ports {
+		mapping, err := composePortMapping(port)
+		if err != nil {
+			return container, warnings, err
+		}
+		if networkMode == ecs.NetworkModeAwsvpc || networkMode == ecs.NetworkModeHost {
+			if mapping.HostPort != nil && *mapping.HostPort != *mapping.ContainerPort {
+				warnings = append(warnings, fmt.Sprintf(
+					"host port %d is replaced by %d, the %s network mode requires the host and container ports to be equal",
+					*mapping.HostPort, *mapping.ContainerPort, networkMode,
+				))
+			}
+			mapping.HostPort = mapping.ContainerPort
+		}
+		container.PortMappings = append(container.PortMappings, mapping)
+	}
+
+	if healthcheck := service.Healthcheck; healthcheck != nil && !healthcheck.Disable {
+		test := healthcheck.Test.values
+		if healthcheck.Test.shell {
+			test = append([]string{"CMD-SHELL"}, test...)
+		}
+		if len(test) > 0 && test[0] != "NONE" {
+			container.HealthCheck = &ecs.HealthCheck{Command: test, Retries: healthcheck.Retries}
+			for _, field := range []struct {
+				value  string
+				target **int64
+			}{
+				{healthcheck.Interval, &container.HealthCheck.Interval},
+				{healthcheck.Timeout, &container.HealthCheck.Timeout},
+				{healthcheck.StartPeriod, &container.HealthCheck.StartPeriod},
+			} {
+				if field.value == "" {
+					continue
+				}
+				duration, err := time.ParseDuration(field.value)
+				if err != nil {
+					return container, warnings, fmt.Errorf("invalid healthcheck duration %s", field.value)
+				}
+				seconds := int64(duration.Seconds())
+				*field.target = &seconds
+			}
+		}
+	}
+
+	if service.Deploy.Replicas != nil {
+		warnings = append(warnings, "deploy.replicas is ignored, set the DesiredCount of the ECS service instead")
+	}
+	memoryLimit, memoryReservation := service.MemLimit, service.MemReservation
+	if service.Deploy.Resources.Limits.Memory != "" {
+		memoryLimit = service.Deploy.Resources.Limits.Memory
+	}
+	if service.Deploy.Resources.Reservations.Memory != "" {
+		memoryReservation = service.Deploy.Resources.Reservations.Memory
+	}
+	if container.Memory, err = composeMemory(memoryLimit); err != nil {
+		return container, warnings, err
+	}
+	if container.MemoryReservation, err = composeMemory(memoryReservation); err != nil {
+		return container, warnings, err
+	}
+	cpus := service.Cpus
+	if service.Deploy.Resources.Limits.Cpus != "" {
+		cpus = service.Deploy.Resources.Limits.Cpus
+	}
+	if cpus != "" {
+		value, err := strconv.ParseFloat(cpus, 64)
+		if err != nil {
+			return container, warnings, fmt.Errorf("invalid cpus %s", cpus)
+		}
+		units := int64(value * 1024)
+		container.Cpu = &units
+	} else if service.CPUShares != nil {
+		container.Cpu = service.CPUShares
+	}
+	if container.Memory == nil && container.MemoryReservation == nil {
+		warnings = append(warnings, "no memory limit or reservation, required by the EC2 launch type")
+	}
+
+	if logging := service.Logging; logging != nil && logging.Driver != "" {
+		container.LogConfiguration = &ecs.LogConfiguration{
+			LogDriver: ecs.LogDriver(logging.Driver),
+			Options:   logging.Options,
+		}
+	}
+
+	dependencies, err := composeDependencies(service.DependsOn)
+	if err != nil {
+		return container, warnings, err
+	}
+	container.DependsOn = dependencies
+	return container, warnings, nil
+}
+
+// SplitCommand splits a command line into arguments, honouring single and double quotes
+func SplitCommand(command string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		quote   rune
+		inArg   bool
+		escaped bool
+	)
+	for _, r := range command {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in %q", command)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+func composeCommand(command composeStrings) ([]string, error) {
+	if command.shell {
+		return SplitCommand(command.values[0])
+	}
+	return command.values, nil
+}
+
+// readEnvFile reads the KEY=VALUE lines of an env file
+func readEnvFile(path string, environment map[string]string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) == 2 {
+			environment[strings.TrimSpace(parts[0])] = parts[1]
+		}
+	}
+	return scanner.Err()
+}
+
+// composePortMapping converts a port of a docker-compose service, in the short ([IP:][HOST:]CONTAINER[/PROTOCOL])
+// or in the long syntax
+func composePortMapping(node yaml.Node) (ecs.PortMapping, error) {
+	var mapping ecs.PortMapping
+	if node.Kind == yaml.MappingNode {
+		var port struct {
+			Target    *int64 `yaml:"target"`
+			Published string `yaml:"published"`
+			Protocol  string `yaml:"protocol"`
+		}
+		if err := node.Decode(&port); err != nil {
+			return mapping, err
+		}
+		if port.Target == nil {
+			return mapping, fmt.Errorf("port without target")
+		}
+		mapping.ContainerPort = port.Target
+		if port.Published != "" {
+			hostPort, err := strconv.ParseInt(port.Published, 10, 64)
+			if err != nil {
+				return mapping, fmt.Errorf("invalid published port %s", port.Published)
+			}
+			mapping.HostPort = &hostPort
+		}
+		if port.Protocol != "" {
+			mapping.Protocol = ecs.TransportProtocol(port.Protocol)
+		}
+		return mapping, nil
+	}
+
+	value := node.Value
+	if index := strings.Index(value, "/"); index >= 0 {
+		mapping.Protocol = ecs.TransportProtocol(value[index+1:])
+		value = value[:index]
+	}
+	parts := strings.Split(value, ":")
+	ports := make([]int64, 0)
+	if len(parts) > 2 {
+		parts = parts[len(parts)-2:]
+	}
+	for _, part := range parts {
+		port, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return mapping, fmt.Errorf("unsupported port %s, port ranges are not supported", node.Value)
+		}
+		ports = append(ports, port)
+	}
+	mapping.ContainerPort = &ports[len(ports)-1]
+	if len(ports) == 2 {
+		mapping.HostPort = &ports[0]
+	}
+	return mapping, nil
+}
+
+// composeMemory converts a docker-compose memory size (e.g. 512m, 1g or a number of bytes) to MiB
+func composeMemory(memory string) (*int64, error) {
+	if memory == "" {
+		return nil, nil
+	}
+	value := strings.TrimSuffix(strings.ToLower(memory), "b")
+	unit := 1.0 / (1024 * 1024)
+	switch {
+	case strings.HasSuffix(value, "k"):
+		unit = 1.0 / 1024
+	case strings.HasSuffix(value, "m"):
+		unit = 1
+	case strings.HasSuffix(value, "g"):
+		unit = 1024
+	}
+	value = strings.TrimRight(value, "kmg")
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid memory size %s", memory)
+	}
+	mebibytes := int64(number * unit)
+	return &mebibytes, nil
+}
+
+// composeDependencies converts the depends_on of a docker-compose service, given either as a
+// list of services or as a mapping of services to conditions
+func composeDependencies(node yaml.Node) ([]ecs.ContainerDependency, error) {
+	dependencies := make([]ecs.ContainerDependency, 0)
+	switch node.Kind {
+	case yaml.SequenceNode:
+		var names []string
+		if err := node.Decode(&names); err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			containerName := name
+			dependencies = append(dependencies, ecs.ContainerDependency{
+				ContainerName: &containerName, Condition: ecs.ContainerConditionStart,
+			})
+		}
+	case yaml.MappingNode:
+		var conditions map[string]struct {
+			Condition string `yaml:"condition"`
+		}
+		if err := node.Decode(&conditions); err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(conditions))
+		for name := range conditions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			containerName := name
+			condition := ecs.ContainerConditionStart
+			switch conditions[name].Condition {
+			case "service_healthy":
+				condition = ecs.ContainerConditionHealthy
+			case "service_completed_successfully":
+				condition = ecs.ContainerConditionSuccess
+			}
+			dependencies = append(dependencies, ecs.ContainerDependency{ContainerName: &containerName, Condition: condition})
+		}
+	}
+	if len(dependencies) == 0 {
+		return nil, nil
+	}
+	return dependencies, nil
+}
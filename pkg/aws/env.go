@@ -0,0 +1,190 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+// EnvDifference is an environment variable or a secret of a container that is not set to the
+// same value in all the compared task definitions
+type EnvDifference struct {
+	Container string
+	Secret    bool
+	Name      string
+	// Values holds the value in each task definition, nil when it is not set. The ValueFrom of
+	// secrets is compared
+	Values []*string
+}
+
+// containerVariables returns the environment variables or the secrets of the containers of a
+// task definition, by container name
+func containerVariables(taskDefinition *ecs.TaskDefinition, secrets bool) map[string]map[string]string {
+	variables := make(map[string]map[string]string)
+	for _, container := range taskDefinition.ContainerDefinitions {
+		values := make(map[string]string)
+		if secrets {
+			for _, secret := range container.Secrets {
+				values[*secret.Name] = *secret.ValueFrom
+			}
+		} else {
+			values = ContainerEnvironment(&container)
+		}
+		variables[*container.Name] = values
+	}
+	return variables
+}
+
+// DiffEnvironments compares the environment variables and the secrets of the containers of task
+// definitions, containers being matched by name
+func DiffEnvironments(taskDefinitions []ecs.TaskDefinition) []EnvDifference {
+	differences := make([]EnvDifference, 0)
+	for _, secrets := range []bool{false, true} {
+		variables := make([]map[string]map[string]string, len(taskDefinitions))
+		containers := make(map[string]bool)
+		names := make(map[string]map[string]bool)
+		for index := range taskDefinitions {
+			variables[index] = containerVariables(&taskDefinitions[index], secrets)
+			for container, values := range variables[index] {
+				if !containers[container] {
+					containers[container] = true
+					names[container] = make(map[string]bool)
+				}
+				for name := range values {
+					names[container][name] = true
+				}
+			}
+		}
+		for _, container := range sortedNames(containers) {
+			for _, name := range sortedNames(names[container]) {
+				difference := EnvDifference{Container: container, Secret: secrets, Name: name}
+				equal := true
+				for index := range taskDefinitions {
+					var value *string
+					if v, ok := variables[index][container][name]; ok {
+						value = &v
+					}
+					difference.Values = append(difference.Values, value)
+					first := difference.Values[0]
+					if (first == nil) != (value == nil) || (value != nil && *first != *value) {
+						equal = false
+					}
+				}
+				if !equal {
+					differences = append(differences, difference)
+				}
+			}
+		}
+	}
+	return differences
+}
+
+// sortedNames returns the names of a set in alphabetical order
+func sortedNames(set map[string]bool) []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EnvChange is a change of an environment variable, Old being nil when it is added and New
+// being nil when it is removed
+type EnvChange struct {
+	Name string
+	Old  *string
+	New  *string
+}
+
+// EnvUpdate is a change of the environment variables of a container of an ECS service
+type EnvUpdate struct {
+	Cluster        string
+	Service        string
+	Container      string
+	TaskDefinition ecs.TaskDefinition
+	Changes        []EnvChange
+
+	newTaskDefinition ecs.TaskDefinition
+	tags              []ecs.Tag
+}
+
+// FindContainerDefinition returns the definition of a container of a task definition, the
+// only container of the task definition when name is empty
+func FindContainerDefinition(taskDefinition *ecs.TaskDefinition, name string) (*ecs.ContainerDefinition, error) {
+	if name == "" {
+		if len(taskDefinition.ContainerDefinitions) != 1 {
+			return nil, fmt.Errorf(
+				"task definition %s has %d containers, select one with --container",
+				*taskDefinition.Family, len(taskDefinition.ContainerDefinitions),
+			)
+		}
+		return &taskDefinition.ContainerDefinitions[0], nil
+	}
+	for index := range taskDefinition.ContainerDefinitions {
+		if *taskDefinition.ContainerDefinitions[index].Name == name {
+			return &taskDefinition.ContainerDefinitions[index], nil
+		}
+	}
+	return nil, fmt.Errorf("container %s not found in task definition %s", name, *taskDefinition.Family)
+}
+
+// PlanEnvUpdate computes the changes of the environment variables of a container of an ECS
+// service, setting the variables of set and removing the ones of unset
+func PlanEnvUpdate(client *ecs.Client, cluster, service, container string, set map[string]string, unset []string) (EnvUpdate, error) {
+	update := EnvUpdate{Cluster: cluster, Service: service}
+	services := DescribeServices(client, cluster, []string{service})
+	if len(services) == 0 {
+		return update, fmt.Errorf("no service %s in cluster %s", service, cluster)
+	}
+	update.TaskDefinition, update.tags = DescribeTaskDefinition(client, *services[0].TaskDefinition)
+	update.newTaskDefinition = copyTaskDefinition(update.TaskDefinition)
+	definition, err := FindContainerDefinition(&update.newTaskDefinition, container)
+	if err != nil {
+		return update, err
+	}
+	update.Container = *definition.Name
+
+	environment := ContainerEnvironment(definition)
+	names := make(map[string]bool)
+	for name := range set {
+		names[name] = true
+	}
+	for _, name := range sortedNames(names) {
+		value := set[name]
+		if old, ok := environment[name]; ok {
+			if old == value {
+				continue
+			}
+			update.Changes = append(update.Changes, EnvChange{Name: name, Old: &old, New: &value})
+		} else {
+			update.Changes = append(update.Changes, EnvChange{Name: name, New: &value})
+		}
+		environment[name] = value
+	}
+	for _, name := range unset {
+		if old, ok := environment[name]; ok {
+			update.Changes = append(update.Changes, EnvChange{Name: name, Old: &old})
+			delete(environment, name)
+		}
+	}
+	SetContainerEnvironment(definition, environment)
+	return update, nil
+}
+
+// Apply registers a new revision of the task definition with the changes and updates the
+// service to use it
+func (u EnvUpdate) Apply(client *ecs.Client) (ecs.TaskDefinition, error) {
+	taskDefinition, err := RegisterTaskDefinition(client, u.newTaskDefinition, u.tags)
+	if err != nil {
+		return taskDefinition, err
+	}
+	_, err = client.UpdateServiceRequest(&ecs.UpdateServiceInput{
+		Cluster:        &u.Cluster,
+		Service:        &u.Service,
+		TaskDefinition: taskDefinition.TaskDefinitionArn,
+	}).Send(context.Background())
+	return taskDefinition, err
+}
@@ -0,0 +1,645 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"gopkg.in/yaml.v3"
+)
+
+// ExportFormats lists the infrastructure as code formats supported by ExportService
+var ExportFormats = []string{"terraform", "cloudformation", "cdk-json"}
+
+// properties holds the CloudFormation properties of a resource, unset values being skipped
+type properties map[string]interface{}
+
+func (p properties) setString(key string, value *string) {
+	if value != nil && *value != "" {
+		p[key] = *value
+	}
+}
+
+func (p properties) setEnum(key string, value string) {
+	if value != "" {
+		p[key] = value
+	}
+}
+
+func (p properties) setInt(key string, value *int64) {
+	if value != nil {
+		p[key] = *value
+	}
+}
+
+func (p properties) setBool(key string, value *bool) {
+	if value != nil {
+		p[key] = *value
+	}
+}
+
+func (p properties) setStrings(key string, values []string) {
+	if len(values) > 0 {
+		p[key] = values
+	}
+}
+
+func (p properties) setList(key string, values []interface{}) {
+	if len(values) > 0 {
+		p[key] = values
+	}
+}
+
+// containerDefinitionProperties returns the CloudFormation properties of a container definition
+func containerDefinitionProperties(container ecs.ContainerDefinition) properties {
+	props := properties{}
+	props.setString("Name", container.Name)
+	props.setString("Image", container.Image)
+	props.setInt("Cpu", container.Cpu)
+	props.setInt("Memory", container.Memory)
+	props.setInt("MemoryReservation", container.MemoryReservation)
+	props.setBool("Essential", container.Essential)
+	props.setStrings("EntryPoint", container.EntryPoint)
+	props.setStrings("Command", container.Command)
+	props.setString("WorkingDirectory", container.WorkingDirectory)
+	props.setString("User", container.User)
+	props.setString("Hostname", container.Hostname)
+	props.setBool("Privileged", container.Privileged)
+	props.setBool("ReadonlyRootFilesystem", container.ReadonlyRootFilesystem)
+	props.setInt("StopTimeout", container.StopTimeout)
+	props.setStrings("Links", container.Links)
+
+	dependencies := make([]interface{}, 0)
+	for _, dependency := range container.DependsOn {
+		dependencies = append(dependencies, properties{
+			"ContainerName": *dependency.ContainerName, "Condition": string(dependency.Condition),
+		})
+	}
+	props.setList("DependsOn", dependencies)
+
+	portMappings := make([]interface{}, 0)
+	for _, portMapping := range container.PortMappings {
+		mapping := properties{}
+		mapping.setInt("ContainerPort", portMapping.ContainerPort)
+		mapping.setInt("HostPort", portMapping.HostPort)
+		mapping.setEnum("Protocol", string(portMapping.Protocol))
+		portMappings = append(portMappings, mapping)
+	}
+	props.setList("PortMappings", portMappings)
+
+	environment := make([]interface{}, 0)
+	for _, variable := range container.Environment {
+		environment = append(environment, properties{"Name": *variable.Name, "Value": *variable.Value})
+	}
+	props.setList("Environment", environment)
+
+	secrets := make([]interface{}, 0)
+	for _, secret := range container.Secrets {
+		secrets = append(secrets, properties{"Name": *secret.Name, "ValueFrom": *secret.ValueFrom})
+	}
+	props.setList("Secrets", secrets)
+
+	if logConfiguration := container.LogConfiguration; logConfiguration != nil {
+		logProps := properties{"LogDriver": string(logConfiguration.LogDriver)}
+		if len(logConfiguration.Options) > 0 {
+			logProps["Options"] = logConfiguration.Options
+		}
+		props["LogConfiguration"] = logProps
+	}
+
+	if healthCheck := container.HealthCheck; healthCheck != nil {
+		healthCheckProps := properties{}
+		healthCheckProps.setStrings("Command", healthCheck.Command)
+		healthCheckProps.setInt("Interval", healthCheck.Interval)
+		healthCheckProps.setInt("Timeout", healthCheck.Timeout)
+		healthCheckProps.setInt("Retries", healthCheck.Retries)
+		healthCheckProps.setInt("StartPeriod", healthCheck.StartPeriod)
+		props["HealthCheck"] = healthCheckProps
+	}
+
+	mountPoints := make([]interface{}, 0)
+	for _, mountPoint := range container.MountPoints {
+		mount := properties{}
+		mount.setString("SourceVolume", mountPoint.SourceVolume)
+		mount.setString("ContainerPath", mountPoint.ContainerPath)
+		mount.setBool("ReadOnly", mountPoint.ReadOnly)
+		mountPoints = append(mountPoints, mount)
+	}
+	props.setList("MountPoints", mountPoints)
+
+	if len(container.DockerLabels) > 0 {
+		props["DockerLabels"] = container.DockerLabels
+	}
+	return props
+}
+
+// taskDefinitionProperties returns the CloudFormation properties of a task definition
+func taskDefinitionProperties(taskDefinition ecs.TaskDefinition) properties {
+	props := properties{}
+	props.setString("Family", taskDefinition.Family)
+	props.setString("Cpu", taskDefinition.Cpu)
+	props.setString("Memory", taskDefinition.Memory)
+	props.setEnum("NetworkMode", string(taskDefinition.NetworkMode))
+	props.setString("ExecutionRoleArn", taskDefinition.ExecutionRoleArn)
+	props.setString("TaskRoleArn", taskDefinition.TaskRoleArn)
+
+	compatibilities := make([]string, 0)
+	for _, compatibility := range taskDefinition.RequiresCompatibilities {
+		compatibilities = append(compatibilities, string(compatibility))
+	}
+	props.setStrings("RequiresCompatibilities", compatibilities)
+
+	containers := make([]interface{}, 0)
+	for _, container := range taskDefinition.ContainerDefinitions {
+		containers = append(containers, containerDefinitionProperties(container))
+	}
+	props.setList("ContainerDefinitions", containers)
+
+	volumes := make([]interface{}, 0)
+	for _, volume := range taskDefinition.Volumes {
+		volumeProps := properties{}
+		volumeProps.setString("Name", volume.Name)
+		if volume.Host != nil && volume.Host.SourcePath != nil {
+			volumeProps["Host"] = properties{"SourcePath": *volume.Host.SourcePath}
+		}
+		volumes = append(volumes, volumeProps)
+	}
+	props.setList("Volumes", volumes)
+	return props
+}
+
+// serviceProperties returns the CloudFormation properties of a service
+func serviceProperties(service ecs.Service, taskDefinition interface{}) properties {
+	props := properties{}
+	props.setString("ServiceName", service.ServiceName)
+	props["Cluster"] = clusterNameFromArn(*service.ClusterArn)
+	props["TaskDefinition"] = taskDefinition
+	if service.SchedulingStrategy != ecs.SchedulingStrategyDaemon {
+		props.setInt("DesiredCount", service.DesiredCount)
+	}
+	props.setEnum("LaunchType", string(service.LaunchType))
+	props.setString("PlatformVersion", service.PlatformVersion)
+	props.setEnum("SchedulingStrategy", string(service.SchedulingStrategy))
+	props.setInt("HealthCheckGracePeriodSeconds", service.HealthCheckGracePeriodSeconds)
+	props.setBool("EnableECSManagedTags", service.EnableECSManagedTags)
+	props.setEnum("PropagateTags", string(service.PropagateTags))
+
+	if deployment := service.DeploymentConfiguration; deployment != nil {
+		deploymentProps := properties{}
+		deploymentProps.setInt("MaximumPercent", deployment.MaximumPercent)
+		deploymentProps.setInt("MinimumHealthyPercent", deployment.MinimumHealthyPercent)
+		props["DeploymentConfiguration"] = deploymentProps
+	}
+
+	loadBalancers := make([]interface{}, 0)
+	for _, loadBalancer := range service.LoadBalancers {
+		loadBalancerProps := properties{}
+		loadBalancerProps.setString("TargetGroupArn", loadBalancer.TargetGroupArn)
+		loadBalancerProps.setString("LoadBalancerName", loadBalancer.LoadBalancerName)
+		loadBalancerProps.setString("ContainerName", loadBalancer.ContainerName)
+		loadBalancerProps.setInt("ContainerPort", loadBalancer.ContainerPort)
+		loadBalancers = append(loadBalancers, loadBalancerProps)
+	}
+	props.setList("LoadBalancers", loadBalancers)
+
+	if network := service.NetworkConfiguration; network != nil && network.AwsvpcConfiguration != nil {
+		awsvpcProps := properties{}
+		awsvpcProps.setStrings("Subnets", network.AwsvpcConfiguration.Subnets)
+		awsvpcProps.setStrings("SecurityGroups", network.AwsvpcConfiguration.SecurityGroups)
+		awsvpcProps.setEnum("AssignPublicIp", string(network.AwsvpcConfiguration.AssignPublicIp))
+		props["NetworkConfiguration"] = properties{"AwsvpcConfiguration": awsvpcProps}
+	}
+
+	constraints := make([]interface{}, 0)
+	for _, constraint := range service.PlacementConstraints {
+		constraintProps := properties{"Type": string(constraint.Type)}
+		constraintProps.setString("Expression", constraint.Expression)
+		constraints = append(constraints, constraintProps)
+	}
+	props.setList("PlacementConstraints", constraints)
+
+	strategies := make([]interface{}, 0)
+	for _, strategy := range service.PlacementStrategy {
+		strategyProps := properties{"Type": string(strategy.Type)}
+		strategyProps.setString("Field", strategy.Field)
+		strategies = append(strategies, strategyProps)
+	}
+	props.setList("PlacementStrategies", strategies)
+
+	capacityProviders := make([]interface{}, 0)
+	for _, item := range service.CapacityProviderStrategy {
+		itemProps := properties{}
+		itemProps.setString("CapacityProvider", item.CapacityProvider)
+		itemProps.setInt("Base", item.Base)
+		itemProps.setInt("Weight", item.Weight)
+		capacityProviders = append(capacityProviders, itemProps)
+	}
+	props.setList("CapacityProviderStrategy", capacityProviders)
+
+	registries := make([]interface{}, 0)
+	for _, registry := range service.ServiceRegistries {
+		registryProps := properties{}
+		registryProps.setString("RegistryArn", registry.RegistryArn)
+		registryProps.setInt("Port", registry.Port)
+		registryProps.setString("ContainerName", registry.ContainerName)
+		registryProps.setInt("ContainerPort", registry.ContainerPort)
+		registries = append(registries, registryProps)
+	}
+	props.setList("ServiceRegistries", registries)
+
+	tags := make([]interface{}, 0)
+	for _, tag := range service.Tags {
+		tags = append(tags, properties{"Key": *tag.Key, "Value": *tag.Value})
+	}
+	props.setList("Tags", tags)
+	return props
+}
+
+// lowerCamelKeys converts the keys of CloudFormation properties to lower camel case, as used by
+// the ECS API and the CDK. Free-form maps (e.g. log options, Docker labels) are left unchanged
+func lowerCamelKeys(value interface{}) interface{} {
+	switch v := value.(type) {
+	case properties:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[lowerCamel(key)] = lowerCamelKeys(item)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for index, item := range v {
+			converted[index] = lowerCamelKeys(item)
+		}
+		return converted
+	}
+	return value
+}
+
+func lowerCamel(key string) string {
+	if key == "EnableECSManagedTags" {
+		return "enableEcsManagedTags"
+	}
+	runes := []rune(key)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// logicalID returns a CloudFormation logical ID from a resource name, e.g. api-prod -> ApiProd
+func logicalID(name string) string {
+	var id strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		id.WriteRune(r)
+	}
+	return id.String()
+}
+
+// TaskDefinitionJSON returns a task definition in the JSON format of the ECS API, as used by
+// aws ecs register-task-definition --cli-input-json
+func TaskDefinitionJSON(taskDefinition ecs.TaskDefinition) (string, error) {
+	content, err := json.MarshalIndent(lowerCamelKeys(taskDefinitionProperties(taskDefinition)), "", "  ")
+	return string(content) + "\n", err
+}
+
+// exportedSetting is a setting that ExportService does not export, and whether it is set
+type exportedSetting struct {
+	name string
+	set  bool
+}
+
+// ExportWarnings lists the settings of a service and its task definition that ExportService does
+// not export, so that the generated code is not mistaken for a complete copy
+func ExportWarnings(service ecs.Service, taskDefinition ecs.TaskDefinition) []string {
+	warnings := make([]string, 0)
+	check := func(subject string, settings []exportedSetting) {
+		for _, setting := range settings {
+			if setting.set {
+				warnings = append(warnings, fmt.Sprintf("%s: %s not exported", subject, setting.name))
+			}
+		}
+	}
+	controller := service.DeploymentController
+	check("service "+*service.ServiceName, []exportedSetting{
+		{"deployment controller", controller != nil && controller.Type != "" && controller.Type != ecs.DeploymentControllerTypeEcs},
+		{"role", service.RoleArn != nil},
+	})
+
+	volumes := []exportedSetting{}
+	for _, volume := range taskDefinition.Volumes {
+		volumes = append(volumes,
+			exportedSetting{fmt.Sprintf("docker configuration of volume %s", *volume.Name), volume.DockerVolumeConfiguration != nil},
+			exportedSetting{fmt.Sprintf("EFS configuration of volume %s", *volume.Name), volume.EfsVolumeConfiguration != nil},
+		)
+	}
+	check("task definition "+*taskDefinition.Family, append([]exportedSetting{
+		{"inference accelerators", len(taskDefinition.InferenceAccelerators) > 0},
+		{"IPC mode", taskDefinition.IpcMode != ""},
+		{"PID mode", taskDefinition.PidMode != ""},
+		{"placement constraints", len(taskDefinition.PlacementConstraints) > 0},
+		{"proxy configuration", taskDefinition.ProxyConfiguration != nil},
+	}, volumes...))
+
+	for _, container := range taskDefinition.ContainerDefinitions {
+		check("container "+*container.Name, []exportedSetting{
+			{"disableNetworking", container.DisableNetworking != nil},
+			{"DNS servers", len(container.DnsServers) > 0},
+			{"DNS search domains", len(container.DnsSearchDomains) > 0},
+			{"docker security options", len(container.DockerSecurityOptions) > 0},
+			{"environment files", len(container.EnvironmentFiles) > 0},
+			{"extra hosts", len(container.ExtraHosts) > 0},
+			{"FireLens configuration", container.FirelensConfiguration != nil},
+			{"interactive", container.Interactive != nil},
+			{"linux parameters", container.LinuxParameters != nil},
+			{"log secret options", container.LogConfiguration != nil && len(container.LogConfiguration.SecretOptions) > 0},
+			{"pseudo terminal", container.PseudoTerminal != nil},
+			{"repository credentials", container.RepositoryCredentials != nil},
+			{"resource requirements", len(container.ResourceRequirements) > 0},
+			{"start timeout", container.StartTimeout != nil},
+			{"system controls", len(container.SystemControls) > 0},
+			{"ulimits", len(container.Ulimits) > 0},
+			{"volumes from", len(container.VolumesFrom) > 0},
+		})
+	}
+	return warnings
+}
+
+// ExportService converts an ECS service and its task definition to infrastructure as code
+// resources, in one of the ExportFormats
+func ExportService(service ecs.Service, taskDefinition ecs.TaskDefinition, format string) (string, error) {
+	switch format {
+	case "terraform":
+		return exportTerraform(service, taskDefinition), nil
+	case "cloudformation":
+		id := logicalID(*service.ServiceName)
+		template := map[string]interface{}{
+			"AWSTemplateFormatVersion": "2010-09-09",
+			"Description":              fmt.Sprintf("ECS service %s exported by ecs export", *service.ServiceName),
+			"Resources": map[string]interface{}{
+				id + "TaskDefinition": map[string]interface{}{
+					"Type":       "AWS::ECS::TaskDefinition",
+					"Properties": taskDefinitionProperties(taskDefinition),
+				},
+				id + "Service": map[string]interface{}{
+					"Type":       "AWS::ECS::Service",
+					"Properties": serviceProperties(service, map[string]string{"Ref": id + "TaskDefinition"}),
+				},
+			},
+		}
+		var content strings.Builder
+		encoder := yaml.NewEncoder(&content)
+		encoder.SetIndent(2)
+		err := encoder.Encode(template)
+		return content.String(), err
+	case "cdk-json":
+		props := map[string]interface{}{
+			"taskDefinition": lowerCamelKeys(taskDefinitionProperties(taskDefinition)),
+			"service":        lowerCamelKeys(serviceProperties(service, *taskDefinition.TaskDefinitionArn)),
+		}
+		content, err := json.MarshalIndent(props, "", "  ")
+		return string(content) + "\n", err
+	}
+	return "", fmt.Errorf("invalid format %s, expected one of %s", format, strings.Join(ExportFormats, ", "))
+}
+
+// hclWriter writes Terraform configuration, aligning the attributes of each block like terraform fmt
+type hclWriter struct {
+	builder strings.Builder
+	indent  string
+	pending [][2]string
+}
+
+func (w *hclWriter) attribute(name, value string) {
+	w.pending = append(w.pending, [2]string{name, value})
+}
+
+func (w *hclWriter) flush() {
+	width := 0
+	for _, attribute := range w.pending {
+		if len(attribute[0]) > width {
+			width = len(attribute[0])
+		}
+	}
+	for _, attribute := range w.pending {
+		fmt.Fprintf(&w.builder, "%s%-*s = %s\n", w.indent, width, attribute[0], attribute[1])
+	}
+	w.pending = nil
+}
+
+func (w *hclWriter) openBlock(header string) {
+	w.flush()
+	if w.indent != "" && !strings.HasSuffix(w.builder.String(), "{\n") {
+		w.builder.WriteString("\n")
+	}
+	fmt.Fprintf(&w.builder, "%s%s {\n", w.indent, header)
+	w.indent += "  "
+}
+
+func (w *hclWriter) closeBlock() {
+	w.flush()
+	w.indent = w.indent[:len(w.indent)-2]
+	fmt.Fprintf(&w.builder, "%s}\n", w.indent)
+}
+
+// hclEscape escapes the Terraform template sequences of a quoted string
+func hclEscape(value string) string {
+	return strings.NewReplacer("${", "$${", "%{", "%%{").Replace(value)
+}
+
+func hclString(value string) string {
+	return hclEscape(strconv.Quote(value))
+}
+
+func hclStrings(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, hclString(value))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// terraformName returns a Terraform resource name from a resource name, e.g. api-prod -> api_prod
+func terraformName(name string) string {
+	converted := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '_'
+	}, name)
+	if converted == "" || unicode.IsDigit(rune(converted[0])) {
+		converted = "service_" + converted
+	}
+	return converted
+}
+
+func exportTerraform(service ecs.Service, taskDefinition ecs.TaskDefinition) string {
+	name := terraformName(*service.ServiceName)
+	clusterName := clusterNameFromArn(*service.ClusterArn)
+	w := &hclWriter{}
+	fmt.Fprintf(&w.builder, "# terraform import aws_ecs_task_definition.%s %s\n", name, *taskDefinition.TaskDefinitionArn)
+	fmt.Fprintf(&w.builder, "# terraform import aws_ecs_service.%s %s/%s\n\n", name, clusterName, *service.ServiceName)
+
+	w.openBlock(fmt.Sprintf("resource \"aws_ecs_task_definition\" %q", name))
+	w.attribute("family", hclString(*taskDefinition.Family))
+	if taskDefinition.NetworkMode != "" {
+		w.attribute("network_mode", hclString(string(taskDefinition.NetworkMode)))
+	}
+	if len(taskDefinition.RequiresCompatibilities) > 0 {
+		compatibilities := make([]string, 0)
+		for _, compatibility := range taskDefinition.RequiresCompatibilities {
+			compatibilities = append(compatibilities, string(compatibility))
+		}
+		w.attribute("requires_compatibilities", hclStrings(compatibilities))
+	}
+	if taskDefinition.Cpu != nil {
+		w.attribute("cpu", hclString(*taskDefinition.Cpu))
+	}
+	if taskDefinition.Memory != nil {
+		w.attribute("memory", hclString(*taskDefinition.Memory))
+	}
+	if taskDefinition.ExecutionRoleArn != nil {
+		w.attribute("execution_role_arn", hclString(*taskDefinition.ExecutionRoleArn))
+	}
+	if taskDefinition.TaskRoleArn != nil {
+		w.attribute("task_role_arn", hclString(*taskDefinition.TaskRoleArn))
+	}
+	containers := make([]interface{}, 0)
+	for _, container := range taskDefinition.ContainerDefinitions {
+		containers = append(containers, lowerCamelKeys(containerDefinitionProperties(container)))
+	}
+	definitions, _ := json.MarshalIndent(containers, w.indent, "  ")
+	w.attribute("container_definitions", "jsonencode("+hclEscape(string(definitions))+")")
+	for _, volume := range taskDefinition.Volumes {
+		w.openBlock("volume")
+		w.attribute("name", hclString(*volume.Name))
+		if volume.Host != nil && volume.Host.SourcePath != nil {
+			w.attribute("host_path", hclString(*volume.Host.SourcePath))
+		}
+		w.closeBlock()
+	}
+	w.closeBlock()
+	w.builder.WriteString("\n")
+
+	w.openBlock(fmt.Sprintf("resource \"aws_ecs_service\" %q", name))
+	w.attribute("name", hclString(*service.ServiceName))
+	w.attribute("cluster", hclString(clusterName))
+	w.attribute("task_definition", fmt.Sprintf("aws_ecs_task_definition.%s.arn", name))
+	if service.SchedulingStrategy != ecs.SchedulingStrategyDaemon {
+		w.attribute("desired_count", fmt.Sprintf("%d", *service.DesiredCount))
+	}
+	if service.LaunchType != "" {
+		w.attribute("launch_type", hclString(string(service.LaunchType)))
+	}
+	if service.PlatformVersion != nil {
+		w.attribute("platform_version", hclString(*service.PlatformVersion))
+	}
+	if service.SchedulingStrategy != "" {
+		w.attribute("scheduling_strategy", hclString(string(service.SchedulingStrategy)))
+	}
+	if deployment := service.DeploymentConfiguration; deployment != nil {
+		if deployment.MaximumPercent != nil {
+			w.attribute("deployment_maximum_percent", fmt.Sprintf("%d", *deployment.MaximumPercent))
+		}
+		if deployment.MinimumHealthyPercent != nil {
+			w.attribute("deployment_minimum_healthy_percent", fmt.Sprintf("%d", *deployment.MinimumHealthyPercent))
+		}
+	}
+	if service.HealthCheckGracePeriodSeconds != nil && *service.HealthCheckGracePeriodSeconds > 0 {
+		w.attribute("health_check_grace_period_seconds", fmt.Sprintf("%d", *service.HealthCheckGracePeriodSeconds))
+	}
+	if service.EnableECSManagedTags != nil {
+		w.attribute("enable_ecs_managed_tags", fmt.Sprintf("%t", *service.EnableECSManagedTags))
+	}
+	if service.PropagateTags != "" {
+		w.attribute("propagate_tags", hclString(string(service.PropagateTags)))
+	}
+	for _, loadBalancer := range service.LoadBalancers {
+		w.openBlock("load_balancer")
+		if loadBalancer.TargetGroupArn != nil {
+			w.attribute("target_group_arn", hclString(*loadBalancer.TargetGroupArn))
+		}
+		if loadBalancer.LoadBalancerName != nil {
+			w.attribute("elb_name", hclString(*loadBalancer.LoadBalancerName))
+		}
+		w.attribute("container_name", hclString(*loadBalancer.ContainerName))
+		w.attribute("container_port", fmt.Sprintf("%d", *loadBalancer.ContainerPort))
+		w.closeBlock()
+	}
+	if network := service.NetworkConfiguration; network != nil && network.AwsvpcConfiguration != nil {
+		w.openBlock("network_configuration")
+		w.attribute("subnets", hclStrings(network.AwsvpcConfiguration.Subnets))
+		if len(network.AwsvpcConfiguration.SecurityGroups) > 0 {
+			w.attribute("security_groups", hclStrings(network.AwsvpcConfiguration.SecurityGroups))
+		}
+		w.attribute("assign_public_ip", fmt.Sprintf("%t", network.AwsvpcConfiguration.AssignPublicIp == ecs.AssignPublicIpEnabled))
+		w.closeBlock()
+	}
+	for _, strategy := range service.PlacementStrategy {
+		w.openBlock("ordered_placement_strategy")
+		w.attribute("type", hclString(string(strategy.Type)))
+		if strategy.Field != nil {
+			w.attribute("field", hclString(*strategy.Field))
+		}
+		w.closeBlock()
+	}
+	for _, constraint := range service.PlacementConstraints {
+		w.openBlock("placement_constraints")
+		w.attribute("type", hclString(string(constraint.Type)))
+		if constraint.Expression != nil {
+			w.attribute("expression", hclString(*constraint.Expression))
+		}
+		w.closeBlock()
+	}
+	for _, item := range service.CapacityProviderStrategy {
+		w.openBlock("capacity_provider_strategy")
+		w.attribute("capacity_provider", hclString(*item.CapacityProvider))
+		if item.Base != nil {
+			w.attribute("base", fmt.Sprintf("%d", *item.Base))
+		}
+		if item.Weight != nil {
+			w.attribute("weight", fmt.Sprintf("%d", *item.Weight))
+		}
+		w.closeBlock()
+	}
+	for _, registry := range service.ServiceRegistries {
+		w.openBlock("service_registries")
+		w.attribute("registry_arn", hclString(*registry.RegistryArn))
+		if registry.Port != nil {
+			w.attribute("port", fmt.Sprintf("%d", *registry.Port))
+		}
+		if registry.ContainerName != nil {
+			w.attribute("container_name", hclString(*registry.ContainerName))
+		}
+		if registry.ContainerPort != nil {
+			w.attribute("container_port", fmt.Sprintf("%d", *registry.ContainerPort))
+		}
+		w.closeBlock()
+	}
+	if len(service.Tags) > 0 {
+		keys := make([]string, 0)
+		values := make(map[string]string)
+		for _, tag := range service.Tags {
+			keys = append(keys, *tag.Key)
+			values[*tag.Key] = *tag.Value
+		}
+		sort.Strings(keys)
+		w.openBlock("tags =")
+		for _, key := range keys {
+			w.attribute(hclString(key), hclString(values[key]))
+		}
+		w.closeBlock()
+	}
+	w.closeBlock()
+	return w.builder.String()
+}
@@ -0,0 +1,235 @@
+package aws
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// instanceMatcher tells whether a container instance matches a cluster query language expression
+type instanceMatcher func(instance *PlacementInstance) bool
+
+// expressionParser is a recursive descent parser for the subset of the ECS cluster query
+// language used in memberOf placement constraints
+type expressionParser struct {
+	tokens []string
+	pos    int
+}
+
+// expressionTokenRegexp splits an expression into quoted strings, operators, parentheses,
+// brackets and words, so that operators do not need to be surrounded by spaces
+var expressionTokenRegexp = regexp.MustCompile(`"[^"]*"|'[^']*'|==|!=|=~|!~|>=|<=|&&|\|\||[()\[\],!<>]|[^\s()\[\],!=<>~&|"']+`)
+
+// parseExpression compiles a cluster query language expression, e.g.
+// "attribute:ecs.instance-type in [t2.small, t2.medium] and attribute:stack == prod"
+func parseExpression(expression string) (instanceMatcher, error) {
+	p := &expressionParser{tokens: expressionTokenRegexp.FindAllString(expression, -1)}
+	matcher, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in expression %q", p.tokens[p.pos], expression)
+	}
+	return matcher, nil
+}
+
+func (p *expressionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *expressionParser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+func (p *expressionParser) parseOr() (instanceMatcher, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" || strings.ToLower(p.peek()) == "or" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(instance *PlacementInstance) bool { return l(instance) || right(instance) }
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseAnd() (instanceMatcher, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" || strings.ToLower(p.peek()) == "and" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(instance *PlacementInstance) bool { return l(instance) && right(instance) }
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseUnary() (instanceMatcher, error) {
+	switch token := p.peek(); {
+	case token == "!" || strings.ToLower(token) == "not":
+		p.next()
+		matcher, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(instance *PlacementInstance) bool { return !matcher(instance) }, nil
+	case token == "(":
+		p.next()
+		matcher, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return matcher, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *expressionParser) parseComparison() (instanceMatcher, error) {
+	subject := p.next()
+	if subject == "" {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	if !strings.HasPrefix(subject, "attribute:") {
+		switch subject {
+		case "ec2InstanceId", "agentConnected", "runningTasksCount", "task:group":
+		default:
+			return nil, fmt.Errorf("unsupported subject %q", subject)
+		}
+	}
+	// Subjects like task:group have a value for each task running on the instance, positive
+	// operators match when one of them matches and negative operators when none matches
+	values := func(instance *PlacementInstance) []string { return instance.attributeValues(subject) }
+	anyValue := func(instance *PlacementInstance, match func(string) bool) bool {
+		for _, value := range values(instance) {
+			if match(value) {
+				return true
+			}
+		}
+		return false
+	}
+
+	operator := strings.ToLower(p.next())
+	// "!exists" and "!in" are split into two tokens
+	if operator == "!" {
+		operator += strings.ToLower(p.next())
+	}
+	switch operator {
+	case "exists":
+		return func(instance *PlacementInstance) bool { return len(values(instance)) > 0 }, nil
+	case "!exists", "not_exists":
+		return func(instance *PlacementInstance) bool { return len(values(instance)) == 0 }, nil
+	case "in", "!in", "not_in":
+		list, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		negate := operator != "in"
+		return func(instance *PlacementInstance) bool {
+			found := anyValue(instance, func(value string) bool {
+				for _, candidate := range list {
+					if value == candidate {
+						return true
+					}
+				}
+				return false
+			})
+			return found != negate
+		}, nil
+	}
+
+	operand := strings.Trim(p.next(), `"'`)
+	if operand == "" {
+		return nil, fmt.Errorf("missing value after %s %s", subject, operator)
+	}
+	switch operator {
+	case "==", "equals":
+		return func(instance *PlacementInstance) bool {
+			return anyValue(instance, func(value string) bool { return value == operand })
+		}, nil
+	case "!=", "not_equals":
+		return func(instance *PlacementInstance) bool {
+			return !anyValue(instance, func(value string) bool { return value == operand })
+		}, nil
+	case "=~", "matches", "!~", "not_matches":
+		re, err := regexp.Compile("^(?:" + operand + ")$")
+		if err != nil {
+			return nil, err
+		}
+		negate := operator == "!~" || operator == "not_matches"
+		return func(instance *PlacementInstance) bool {
+			return anyValue(instance, re.MatchString) != negate
+		}, nil
+	case ">", "greater_than", ">=", "greater_than_equal", "<", "less_than", "<=", "less_than_equal":
+		return func(instance *PlacementInstance) bool {
+			return anyValue(instance, func(value string) bool {
+				cmp := compareValues(value, operand)
+				switch operator {
+				case ">", "greater_than":
+					return cmp > 0
+				case ">=", "greater_than_equal":
+					return cmp >= 0
+				case "<", "less_than":
+					return cmp < 0
+				}
+				return cmp <= 0
+			})
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported operator %q", operator)
+}
+
+func (p *expressionParser) parseList() ([]string, error) {
+	if p.next() != "[" {
+		return nil, fmt.Errorf("expected a list of values")
+	}
+	values := make([]string, 0)
+	for {
+		token := p.next()
+		switch token {
+		case "]":
+			return values, nil
+		case ",":
+		case "":
+			return nil, fmt.Errorf("missing closing bracket")
+		default:
+			values = append(values, strings.Trim(token, `"'`))
+		}
+	}
+}
+
+// compareValues compares two values numerically when possible, as strings otherwise
+func compareValues(v1, v2 string) int {
+	f1, err1 := strconv.ParseFloat(v1, 64)
+	f2, err2 := strconv.ParseFloat(v2, 64)
+	if err1 == nil && err2 == nil {
+		switch {
+		case f1 < f2:
+			return -1
+		case f1 > f2:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(v1, v2)
+}
@@ -0,0 +1,232 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+func testInstance(id string, cpu, memory int64, attributes map[string]string, taskGroups ...string) *PlacementInstance {
+	return &PlacementInstance{
+		InstanceResources: InstanceResources{
+			InstanceID: id, Active: true,
+			RegisteredCPU: cpu, RemainingCPU: cpu, RegisteredMemory: memory, RemainingMemory: memory,
+		},
+		ContainerInstanceArn: "arn:aws:ecs:us-east-1:123456789012:container-instance/" + id,
+		TaskGroups:           taskGroups,
+		attributes:           attributes,
+		agentConnected:       true,
+		runningTasks:         int64(len(taskGroups)),
+		reservedPorts:        make(map[int64]bool),
+	}
+}
+
+func TestParseExpression(t *testing.T) {
+	instance := testInstance("i-0123456789abcdef0", 2048, 4096, map[string]string{
+		"ecs.instance-type":     "t3.medium",
+		"ecs.availability-zone": "us-east-1a",
+		"stack":                 "prod",
+	}, "service:api", "family:batch")
+
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{"attribute:ecs.instance-type == t3.medium", true},
+		{"attribute:ecs.instance-type==t3.medium", true},
+		{"attribute:ecs.instance-type != t3.medium", false},
+		{"attribute:ecs.instance-type=~t3.*", true},
+		{"attribute:ecs.instance-type =~ t2.*", false},
+		{"attribute:ecs.instance-type!~t2.*", true},
+		{"attribute:ecs.instance-type in [t2.small, t3.medium]", true},
+		{"attribute:ecs.instance-type in [t2.small,t2.medium]", false},
+		{"attribute:ecs.instance-type !in [t2.small, t2.medium]", true},
+		{"attribute:ecs.instance-type not_in [t3.medium]", false},
+		{"attribute:stack exists", true},
+		{"attribute:missing exists", false},
+		{"attribute:missing !exists", true},
+		{`attribute:stack == "prod"`, true},
+		{"attribute:stack==prod and attribute:ecs.availability-zone!=us-east-1b", true},
+		{"attribute:stack==prod&&attribute:ecs.availability-zone==us-east-1b", false},
+		{"attribute:stack==dev||attribute:ecs.availability-zone==us-east-1a", true},
+		{"attribute:stack == dev or attribute:stack == staging", false},
+		{"!(attribute:stack==dev)", true},
+		{"not (attribute:stack == prod)", false},
+		{"(attribute:stack==dev or attribute:stack==prod) and attribute:ecs.instance-type=~t3.*", true},
+		{"runningTasksCount >= 2", true},
+		{"runningTasksCount<2", false},
+		{"ec2InstanceId == i-0123456789abcdef0", true},
+		{"agentConnected == true", true},
+		{"task:group == service:api", true},
+		{"task:group==service:worker", false},
+		{"task:group != service:worker", true},
+		{"not(task:group == family:batch)", false},
+		{"task:group in [service:worker, family:batch]", true},
+	}
+	for _, test := range tests {
+		matcher, err := parseExpression(test.expression)
+		if err != nil {
+			t.Errorf("parseExpression(%q) failed: %s", test.expression, err)
+			continue
+		}
+		if got := matcher(instance); got != test.want {
+			t.Errorf("parseExpression(%q) matched %v, want %v", test.expression, got, test.want)
+		}
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	for _, expression := range []string{
+		"",
+		"attribute:stack ==",
+		"attribute:stack",
+		"(attribute:stack == prod",
+		"attribute:stack == prod)",
+		"attribute:stack in [prod",
+		"attribute:stack in prod",
+		"attribute:stack ~~ prod",
+		"instanceType == t3.medium",
+		"attribute:stack =~ (",
+	} {
+		if _, err := parseExpression(expression); err == nil {
+			t.Errorf("parseExpression(%q) succeeded, want an error", expression)
+		}
+	}
+}
+
+func TestPlacementSimulate(t *testing.T) {
+	zone := func(az string) map[string]string { return map[string]string{"ecs.availability-zone": az} }
+	tests := []struct {
+		name      string
+		placement Placement
+		instances func() []*PlacementInstance
+		count     int
+		placed    int
+		want      map[string]int64
+	}{
+		{
+			name: "spread across availability zones then instances",
+			placement: Placement{CPU: 256, Memory: 512, Strategies: []ecs.PlacementStrategy{
+				{Type: ecs.PlacementStrategyTypeSpread, Field: aws.String("attribute:ecs.availability-zone")},
+				{Type: ecs.PlacementStrategyTypeSpread, Field: aws.String("instanceId")},
+			}},
+			instances: func() []*PlacementInstance {
+				return []*PlacementInstance{
+					testInstance("i-a1", 4096, 8192, zone("us-east-1a")),
+					testInstance("i-a2", 4096, 8192, zone("us-east-1a")),
+					testInstance("i-b1", 4096, 8192, zone("us-east-1b")),
+				}
+			},
+			count:  4,
+			placed: 4,
+			want:   map[string]int64{"i-a1": 1, "i-a2": 1, "i-b1": 2},
+		},
+		{
+			name: "spread across instances",
+			placement: Placement{CPU: 256, Memory: 512, Strategies: []ecs.PlacementStrategy{
+				{Type: ecs.PlacementStrategyTypeSpread, Field: aws.String("instanceId")},
+			}},
+			instances: func() []*PlacementInstance {
+				return []*PlacementInstance{
+					testInstance("i-1", 4096, 8192, zone("us-east-1a")),
+					testInstance("i-2", 4096, 8192, zone("us-east-1a")),
+					testInstance("i-3", 4096, 8192, zone("us-east-1b")),
+				}
+			},
+			count:  6,
+			placed: 6,
+			want:   map[string]int64{"i-1": 2, "i-2": 2, "i-3": 2},
+		},
+		{
+			name: "binpack on memory fills the fullest instance first",
+			placement: Placement{CPU: 256, Memory: 1024, Strategies: []ecs.PlacementStrategy{
+				{Type: ecs.PlacementStrategyTypeBinpack, Field: aws.String("memory")},
+			}},
+			instances: func() []*PlacementInstance {
+				small := testInstance("i-small", 4096, 2048, zone("us-east-1a"))
+				return []*PlacementInstance{testInstance("i-large", 4096, 8192, zone("us-east-1a")), small}
+			},
+			count:  3,
+			placed: 3,
+			want:   map[string]int64{"i-small": 2, "i-large": 1},
+		},
+		{
+			name: "binpack on cpu",
+			placement: Placement{CPU: 1024, Memory: 256, Strategies: []ecs.PlacementStrategy{
+				{Type: ecs.PlacementStrategyTypeBinpack, Field: aws.String("cpu")},
+			}},
+			instances: func() []*PlacementInstance {
+				return []*PlacementInstance{
+					testInstance("i-large", 4096, 8192, zone("us-east-1a")),
+					testInstance("i-small", 2048, 8192, zone("us-east-1a")),
+				}
+			},
+			count:  2,
+			placed: 2,
+			want:   map[string]int64{"i-small": 2},
+		},
+		{
+			name: "distinctInstance and memberOf constraints",
+			placement: Placement{CPU: 256, Memory: 512, Constraints: []ecs.PlacementConstraint{
+				{Type: ecs.PlacementConstraintTypeDistinctInstance},
+				{Type: ecs.PlacementConstraintTypeMemberOf, Expression: aws.String("attribute:ecs.availability-zone!=us-east-1c")},
+			}},
+			instances: func() []*PlacementInstance {
+				return []*PlacementInstance{
+					testInstance("i-a", 4096, 8192, zone("us-east-1a")),
+					testInstance("i-b", 4096, 8192, zone("us-east-1b")),
+					testInstance("i-c", 4096, 8192, zone("us-east-1c")),
+				}
+			},
+			count:  3,
+			placed: 2,
+			want:   map[string]int64{"i-a": 1, "i-b": 1},
+		},
+		{
+			name: "task:group constraint sees the tasks placed by the simulation",
+			placement: Placement{Group: "service:api", CPU: 256, Memory: 512, Constraints: []ecs.PlacementConstraint{
+				{Type: ecs.PlacementConstraintTypeMemberOf, Expression: aws.String("not(task:group==service:api)")},
+			}},
+			instances: func() []*PlacementInstance {
+				return []*PlacementInstance{
+					testInstance("i-1", 4096, 8192, zone("us-east-1a"), "service:api"),
+					testInstance("i-2", 4096, 8192, zone("us-east-1a")),
+					testInstance("i-3", 4096, 8192, zone("us-east-1b"), "service:worker"),
+				}
+			},
+			count:  3,
+			placed: 2,
+			want:   map[string]int64{"i-2": 1, "i-3": 1},
+		},
+		{
+			name:      "not enough resources",
+			placement: Placement{CPU: 2048, Memory: 512},
+			instances: func() []*PlacementInstance {
+				return []*PlacementInstance{testInstance("i-1", 4096, 8192, zone("us-east-1a"))}
+			},
+			count:  3,
+			placed: 2,
+			want:   map[string]int64{"i-1": 2},
+		},
+	}
+	for _, test := range tests {
+		instances := test.instances()
+		placed, reasons, err := test.placement.Simulate(instances, test.count)
+		if err != nil {
+			t.Errorf("%s: Simulate failed: %s", test.name, err)
+			continue
+		}
+		if placed != test.placed {
+			t.Errorf("%s: placed %d tasks, want %d", test.name, placed, test.placed)
+		}
+		if placed < test.count && len(reasons) == 0 {
+			t.Errorf("%s: no reason given for the tasks not placed", test.name)
+		}
+		for _, instance := range instances {
+			if instance.Placed != test.want[instance.InstanceID] {
+				t.Errorf("%s: placed %d tasks on %s, want %d", test.name, instance.Placed, instance.InstanceID, test.want[instance.InstanceID])
+			}
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// dockerHubRegistry is the registry of the images without a registry host
+const dockerHubRegistry = "registry-1.docker.io"
+
+var ecrRegistryRegexp = regexp.MustCompile(`^([0-9]{12})\.dkr\.ecr\.([a-z0-9-]+)\.amazonaws\.com(\.cn)?$`)
+
+// manifestMediaTypes lists the manifest formats accepted from registries, so that they return
+// the digest of the manifest list of multi-architecture images like ECS does
+var manifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}
+
+// ImageReference is a Docker image name split into its parts
+type ImageReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseImage splits a Docker image name like registry/repository:tag or repository@digest,
+// images without a registry coming from the Docker Hub and images without a tag or digest
+// using the latest tag
+func ParseImage(image string) ImageReference {
+	var reference ImageReference
+	if index := strings.Index(image, "@"); index >= 0 {
+		image, reference.Digest = image[:index], image[index+1:]
+	}
+	// The tag follows the last colon, unless it is the port of the registry
+	if index := strings.LastIndex(image, ":"); index >= 0 && !strings.Contains(image[index:], "/") {
+		image, reference.Tag = image[:index], image[index+1:]
+	}
+	if reference.Tag == "" && reference.Digest == "" {
+		reference.Tag = "latest"
+	}
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		reference.Registry, reference.Repository = parts[0], parts[1]
+	} else {
+		reference.Registry, reference.Repository = dockerHubRegistry, image
+	}
+	if reference.Registry == "docker.io" || reference.Registry == "index.docker.io" {
+		reference.Registry = dockerHubRegistry
+	}
+	// Official images of the Docker Hub are in the library namespace
+	if reference.Registry == dockerHubRegistry && !strings.Contains(reference.Repository, "/") {
+		reference.Repository = "library/" + reference.Repository
+	}
+	return reference
+}
+
+// registryCredentials are the credentials of a private registry, stored in a Secrets Manager
+// secret as the repositoryCredentials of a container definition
+type registryCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// ImageResolver resolves the current digest of image tags, from the ECR API for ECR
+// repositories and from the registry v2 API for other registries. Digests are cached
+type ImageResolver struct {
+	client      *ecs.Client
+	http        *http.Client
+	digests     map[string]string
+	errors      map[string]error
+	credentials map[string]*registryCredentials
+}
+
+// NewImageResolver returns an ImageResolver using the configuration of an ECS client for ECR
+func NewImageResolver(client *ecs.Client) *ImageResolver {
+	return &ImageResolver{
+		client:      client,
+		http:        &http.Client{Timeout: 30 * time.Second},
+		digests:     make(map[string]string),
+		errors:      make(map[string]error),
+		credentials: make(map[string]*registryCredentials),
+	}
+}
+
+// Digest returns the current digest of an image in its registry, the digest itself for images
+// pinned by digest. Private registries other than ECR are queried with the credentials of the
+// Secrets Manager secret credentialsParameter, if any, and anonymously otherwise
+func (r *ImageResolver) Digest(image, credentialsParameter string) (string, error) {
+	key := image + " " + credentialsParameter
+	if digest, ok := r.digests[key]; ok {
+		return digest, r.errors[key]
+	}
+	reference := ParseImage(image)
+	var digest string
+	var err error
+	switch {
+	case reference.Digest != "":
+		digest = reference.Digest
+	case ecrRegistryRegexp.MatchString(reference.Registry):
+		digest, err = r.ecrDigest(reference)
+	default:
+		var credentials *registryCredentials
+		if credentialsParameter != "" {
+			credentials, err = r.registryCredentials(credentialsParameter)
+		}
+		if err == nil {
+			digest, err = r.registryDigest(reference, credentials)
+		}
+	}
+	r.digests[key], r.errors[key] = digest, err
+	return digest, err
+}
+
+// registryCredentials reads the username and password of a private registry from a Secrets
+// Manager secret, in the region of its ARN
+func (r *ImageResolver) registryCredentials(secretID string) (*registryCredentials, error) {
+	if credentials, ok := r.credentials[secretID]; ok {
+		return credentials, nil
+	}
+	cfg := r.client.Config.Copy()
+	if fields := strings.Split(secretID, ":"); strings.HasPrefix(secretID, "arn:") && len(fields) >= 7 {
+		cfg.Region = fields[3]
+	}
+	resp, err := secretsmanager.New(cfg).GetSecretValueRequest(&secretsmanager.GetSecretValueInput{
+		SecretId: &secretID,
+	}).Send(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the registry credentials %s: %s", secretID, err.Error())
+	}
+	var credentials registryCredentials
+	if resp.SecretString == nil || json.Unmarshal([]byte(*resp.SecretString), &credentials) != nil || credentials.Username == "" {
+		return nil, fmt.Errorf("registry credentials %s are not a JSON object with a username and a password", secretID)
+	}
+	r.credentials[secretID] = &credentials
+	return &credentials, nil
+}
+
+func (r *ImageResolver) ecrDigest(reference ImageReference) (string, error) {
+	groups := ecrRegistryRegexp.FindStringSubmatch(reference.Registry)
+	cfg := r.client.Config.Copy()
+	cfg.Region = groups[2]
+	resp, err := ecr.New(cfg).DescribeImagesRequest(&ecr.DescribeImagesInput{
+		RegistryId:     &groups[1],
+		RepositoryName: &reference.Repository,
+		ImageIds:       []ecr.ImageIdentifier{{ImageTag: &reference.Tag}},
+	}).Send(context.Background())
+	if err != nil {
+		return "", err
+	}
+	if len(resp.ImageDetails) == 0 || resp.ImageDetails[0].ImageDigest == nil {
+		return "", fmt.Errorf("tag %s not found in repository %s", reference.Tag, reference.Repository)
+	}
+	return *resp.ImageDetails[0].ImageDigest, nil
+}
+
+// registryDigest reads the digest of a tag from the Docker-Content-Digest header of its manifest,
+// authenticating with the credentials, if any, when the registry requires it
+func (r *ImageResolver) registryDigest(reference ImageReference, credentials *registryCredentials) (string, error) {
+	url := fmt.Sprintf("https://%s/v2/%s/manifests/%s", reference.Registry, reference.Repository, reference.Tag)
+	resp, err := r.headManifest(url, "")
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("Www-Authenticate")
+		var authorization string
+		if strings.HasPrefix(strings.ToLower(challenge), "basic ") && credentials != nil {
+			authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials.Username+":"+credentials.Password))
+		} else {
+			token, err := r.registryToken(challenge, credentials)
+			if err != nil {
+				return "", err
+			}
+			authorization = "Bearer " + token
+		}
+		if resp, err = r.headManifest(url, authorization); err != nil {
+			return "", err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get manifest of %s:%s: %s", reference.Repository, reference.Tag, resp.Status)
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("registry %s did not return the digest of %s:%s", reference.Registry, reference.Repository, reference.Tag)
+	}
+	return digest, nil
+}
+
+func (r *ImageResolver) headManifest(url, authorization string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodHead, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+var bearerParamRegexp = regexp.MustCompile(`([a-z]+)="([^"]*)"`)
+
+// registryToken gets a token from the authorization server of a registry, described by the
+// WWW-Authenticate header of its response, anonymously when there are no credentials
+func (r *ImageResolver) registryToken(challenge string, credentials *registryCredentials) (string, error) {
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return "", fmt.Errorf("unsupported registry authentication %q", challenge)
+	}
+	params := make(map[string]string)
+	for _, groups := range bearerParamRegexp.FindAllStringSubmatch(challenge, -1) {
+		params[groups[1]] = groups[2]
+	}
+	req, err := http.NewRequest(http.MethodGet, params["realm"], nil)
+	if err != nil {
+		return "", err
+	}
+	query := req.URL.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	req.URL.RawQuery = query.Encode()
+	if credentials != nil {
+		req.SetBasicAuth(credentials.Username, credentials.Password)
+	}
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get a registry token from %s: %s", params["realm"], resp.Status)
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.Token == "" {
+		return body.AccessToken, nil
+	}
+	return body.Token, nil
+}
+
+// ShortDigest shortens a digest like sha256:0123... to its first 12 hexadecimal characters
+func ShortDigest(digest string) string {
+	if index := strings.Index(digest, ":"); index >= 0 && len(digest) > index+13 {
+		return digest[:index+13]
+	}
+	return digest
+}
@@ -0,0 +1,50 @@
+package aws
+
+import "testing"
+
+func TestParseImage(t *testing.T) {
+	digest := "sha256:4c0fdaa8b6341bfdeca5f18f7837462c80cff90527ee35ef185571e1c327beac"
+	tests := []struct {
+		image string
+		want  ImageReference
+	}{
+		{"nginx", ImageReference{Registry: dockerHubRegistry, Repository: "library/nginx", Tag: "latest"}},
+		{"nginx:1.25", ImageReference{Registry: dockerHubRegistry, Repository: "library/nginx", Tag: "1.25"}},
+		{"grafana/grafana:10.0.0", ImageReference{Registry: dockerHubRegistry, Repository: "grafana/grafana", Tag: "10.0.0"}},
+		{"docker.io/nginx", ImageReference{Registry: dockerHubRegistry, Repository: "library/nginx", Tag: "latest"}},
+		{"docker.io/grafana/grafana", ImageReference{Registry: dockerHubRegistry, Repository: "grafana/grafana", Tag: "latest"}},
+		{"index.docker.io/library/nginx:1.25", ImageReference{Registry: dockerHubRegistry, Repository: "library/nginx", Tag: "1.25"}},
+		{"localhost/app", ImageReference{Registry: "localhost", Repository: "app", Tag: "latest"}},
+		{"localhost:5000/app", ImageReference{Registry: "localhost:5000", Repository: "app", Tag: "latest"}},
+		{"localhost:5000/team/app:2.1", ImageReference{Registry: "localhost:5000", Repository: "team/app", Tag: "2.1"}},
+		{"ghcr.io/acme/app:v1", ImageReference{Registry: "ghcr.io", Repository: "acme/app", Tag: "v1"}},
+		{
+			"123456789012.dkr.ecr.us-east-1.amazonaws.com/acme/jenkins:2.77",
+			ImageReference{Registry: "123456789012.dkr.ecr.us-east-1.amazonaws.com", Repository: "acme/jenkins", Tag: "2.77"},
+		},
+		{"nginx@" + digest, ImageReference{Registry: dockerHubRegistry, Repository: "library/nginx", Digest: digest}},
+		{"nginx:1.25@" + digest, ImageReference{Registry: dockerHubRegistry, Repository: "library/nginx", Tag: "1.25", Digest: digest}},
+		{"localhost:5000/app@" + digest, ImageReference{Registry: "localhost:5000", Repository: "app", Digest: digest}},
+	}
+	for _, test := range tests {
+		if got := ParseImage(test.image); got != test.want {
+			t.Errorf("ParseImage(%q) = %+v, want %+v", test.image, got, test.want)
+		}
+	}
+}
+
+func TestShortDigest(t *testing.T) {
+	tests := []struct {
+		digest string
+		want   string
+	}{
+		{"sha256:4c0fdaa8b6341bfdeca5f18f7837462c80cff90527ee35ef185571e1c327beac", "sha256:4c0fdaa8b634"},
+		{"sha256:4c0f", "sha256:4c0f"},
+		{"unknown", "unknown"},
+	}
+	for _, test := range tests {
+		if got := ShortDigest(test.digest); got != test.want {
+			t.Errorf("ShortDigest(%q) = %q, want %q", test.digest, got, test.want)
+		}
+	}
+}
@@ -1,15 +1,114 @@
 package aws
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	"github.com/fatih/color"
 )
 
+// ListContainerInstances describes the container instances registered in the ECS cluster
+func ListContainerInstances(client *ecs.Client, clusterName string) []ecs.ContainerInstance {
+	req := client.ListContainerInstancesRequest(&ecs.ListContainerInstancesInput{Cluster: &clusterName})
+	p := ecs.NewListContainerInstancesPaginator(req)
+
+	instanceArns := make([]string, 0)
+	for p.Next(context.Background()) {
+		page := p.CurrentPage()
+		instanceArns = append(instanceArns, page.ContainerInstanceArns...)
+	}
+	if err := p.Err(); err != nil {
+		fmt.Println("Failed to list container instances: " + err.Error())
+		os.Exit(1)
+	}
+
+	return DescribeContainerInstances(client, clusterName, instanceArns)
+}
+
+// DescribeContainerInstances describes a list of container instances registered in the ECS cluster
+func DescribeContainerInstances(client *ecs.Client, clusterName string, containerInstanceArns []string) []ecs.ContainerInstance {
+	containerInstances := make([]ecs.ContainerInstance, 0)
+	for _, arns := range chunk(containerInstanceArns, 100) {
+		if len(arns) > 0 {
+			resp, err := client.DescribeContainerInstancesRequest(&ecs.DescribeContainerInstancesInput{
+				Cluster:            &clusterName,
+				ContainerInstances: arns,
+			}).Send(context.Background())
+			if err != nil {
+				fmt.Println("Failed to describe container instances: " + err.Error())
+				os.Exit(1)
+			}
+			containerInstances = append(containerInstances, resp.ContainerInstances...)
+		}
+	}
+	return containerInstances
+}
+
+// UpdateContainerInstancesState sets the status of container instances to ACTIVE or DRAINING
+func UpdateContainerInstancesState(client *ecs.Client, clusterName string, containerInstanceArns []string, status ecs.ContainerInstanceStatus) {
+	for _, arns := range chunk(containerInstanceArns, 10) {
+		if len(arns) == 0 {
+			continue
+		}
+		resp, err := client.UpdateContainerInstancesStateRequest(&ecs.UpdateContainerInstancesStateInput{
+			Cluster:            &clusterName,
+			ContainerInstances: arns,
+			Status:             status,
+		}).Send(context.Background())
+		if err != nil {
+			fmt.Println("Failed to update container instances state: " + err.Error())
+			os.Exit(1)
+		}
+		if len(resp.Failures) > 0 {
+			for _, failure := range resp.Failures {
+				fmt.Println("Failed to update container instance state: " + *failure.Reason)
+			}
+			os.Exit(1)
+		}
+	}
+}
+
+// ContainerInstanceArn returns the ARN of the container instance running on an EC2 instance,
+// or an empty string if the EC2 instance is not registered in the ECS cluster
+func ContainerInstanceArn(client *ecs.Client, clusterName, instanceID string) string {
+	for _, cinst := range ListContainerInstances(client, clusterName) {
+		if *cinst.Ec2InstanceId == instanceID {
+			return *cinst.ContainerInstanceArn
+		}
+	}
+	return ""
+}
+
+// Ec2InstanceIds maps the ARNs of the container instances of the ECS cluster to their EC2 instance ID
+func Ec2InstanceIds(client *ecs.Client, clusterName string) map[string]string {
+	instanceIds := make(map[string]string)
+	for _, cinst := range ListContainerInstances(client, clusterName) {
+		instanceIds[*cinst.ContainerInstanceArn] = *cinst.Ec2InstanceId
+	}
+	return instanceIds
+}
+
+// TerminateInstanceInASG terminates an EC2 instance without decrementing the desired capacity
+// of its Auto Scaling group, so that it is replaced by a new instance
+func TerminateInstanceInASG(client *ecs.Client, instanceID string) {
+	asgClient := autoscaling.New(client.Config)
+	_, err := asgClient.TerminateInstanceInAutoScalingGroupRequest(&autoscaling.TerminateInstanceInAutoScalingGroupInput{
+		InstanceId:                     &instanceID,
+		ShouldDecrementDesiredCapacity: aws.Bool(false),
+	}).Send(context.Background())
+	if err != nil {
+		fmt.Printf("Failed to terminate instance %s: %s\n", instanceID, err.Error())
+		os.Exit(1)
+	}
+}
+
 // DetailedInstanceOutput prints a container instance's attributes and capabilities
 func DetailedInstanceOutput(containerInstance *ecs.ContainerInstance) {
 	var line string
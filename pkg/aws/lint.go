@@ -0,0 +1,199 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+// Finding is a violation of a lint rule by a task definition
+type Finding struct {
+	Rule           string `json:"rule"`
+	TaskDefinition string `json:"taskDefinition"`
+	Container      string `json:"container,omitempty"`
+	Message        string `json:"message"`
+}
+
+// LintRule checks a best practice on task definitions
+type LintRule struct {
+	Name        string
+	Description string
+	check       func(taskDefinition *ecs.TaskDefinition) []Finding
+}
+
+// containerRule builds the check of a rule applying to each container of a task definition,
+// the check returning the message of the finding or an empty string
+func containerRule(check func(container *ecs.ContainerDefinition) string) func(*ecs.TaskDefinition) []Finding {
+	return func(taskDefinition *ecs.TaskDefinition) []Finding {
+		findings := make([]Finding, 0)
+		for index := range taskDefinition.ContainerDefinitions {
+			container := &taskDefinition.ContainerDefinitions[index]
+			if message := check(container); message != "" {
+				findings = append(findings, Finding{Container: *container.Name, Message: message})
+			}
+		}
+		return findings
+	}
+}
+
+// LintRules lists the rules checked by LintTaskDefinition
+var LintRules = []LintRule{
+	{
+		Name:        "image-tag",
+		Description: "Images use a fixed tag or digest, not latest",
+		check: containerRule(func(container *ecs.ContainerDefinition) string {
+			image := *container.Image
+			if strings.Contains(image, "@") {
+				return ""
+			}
+			// The tag follows the last colon, unless it is the port of the registry
+			if index := strings.LastIndex(image, ":"); index < 0 || strings.Contains(image[index:], "/") {
+				return fmt.Sprintf("image %s has no tag and defaults to latest", image)
+			} else if image[index+1:] == "latest" {
+				return fmt.Sprintf("image %s uses the latest tag", image)
+			}
+			return ""
+		}),
+	},
+	{
+		Name:        "resources",
+		Description: "Containers set their CPU and memory",
+		check: containerRule(func(container *ecs.ContainerDefinition) string {
+			missing := make([]string, 0)
+			if container.Cpu == nil || *container.Cpu == 0 {
+				missing = append(missing, "CPU")
+			}
+			if container.Memory == nil && container.MemoryReservation == nil {
+				missing = append(missing, "memory")
+			}
+			if len(missing) > 0 {
+				return strings.Join(missing, " and ") + " not set"
+			}
+			return ""
+		}),
+	},
+	{
+		Name:        "healthcheck",
+		Description: "Containers define a healthcheck",
+		check: containerRule(func(container *ecs.ContainerDefinition) string {
+			if container.HealthCheck == nil {
+				return "no healthcheck defined"
+			}
+			return ""
+		}),
+	},
+	{
+		Name:        "read-only-root-filesystem",
+		Description: "Containers use a read-only root filesystem",
+		check: containerRule(func(container *ecs.ContainerDefinition) string {
+			if container.ReadonlyRootFilesystem == nil || !*container.ReadonlyRootFilesystem {
+				return "root filesystem is writable"
+			}
+			return ""
+		}),
+	},
+	{
+		Name:        "plaintext-secrets",
+		Description: "Secrets are passed with Secrets, not as plaintext environment variables",
+		check: containerRule(func(container *ecs.ContainerDefinition) string {
+			names := make([]string, 0)
+			for _, variable := range container.Environment {
+				if LikelySecret(*variable.Name) {
+					names = append(names, *variable.Name)
+				}
+			}
+			if len(names) > 0 {
+				return "plaintext secrets in environment: " + strings.Join(names, ", ")
+			}
+			return ""
+		}),
+	},
+	{
+		Name:        "log-configuration",
+		Description: "Containers send their logs to a log driver",
+		check: containerRule(func(container *ecs.ContainerDefinition) string {
+			if container.LogConfiguration == nil {
+				return "no log configuration"
+			}
+			return ""
+		}),
+	},
+	{
+		Name:        "non-root-user",
+		Description: "Containers run as a non-root user",
+		check: containerRule(func(container *ecs.ContainerDefinition) string {
+			if container.User == nil || *container.User == "" {
+				return "no user set, the container runs as the user of the image (root by default)"
+			}
+			user := strings.SplitN(*container.User, ":", 2)[0]
+			if user == "root" || user == "0" {
+				return "container runs as root"
+			}
+			return ""
+		}),
+	},
+	{
+		Name:        "essential-container",
+		Description: "At least one container is essential",
+		check: func(taskDefinition *ecs.TaskDefinition) []Finding {
+			for _, container := range taskDefinition.ContainerDefinitions {
+				// Containers are essential by default
+				if container.Essential == nil || *container.Essential {
+					return nil
+				}
+			}
+			return []Finding{{Message: "no essential container"}}
+		},
+	},
+}
+
+// LintTaskDefinition checks a task definition against the enabled rules
+func LintTaskDefinition(taskDefinition ecs.TaskDefinition, enabled map[string]bool) []Finding {
+	name := *taskDefinition.Family
+	if taskDefinition.Revision != nil {
+		name = fmt.Sprintf("%s:%d", name, *taskDefinition.Revision)
+	}
+	findings := make([]Finding, 0)
+	for _, rule := range LintRules {
+		if !enabled[rule.Name] {
+			continue
+		}
+		for _, finding := range rule.check(&taskDefinition) {
+			finding.Rule = rule.Name
+			finding.TaskDefinition = name
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+// ReadTaskDefinition reads a task definition from a JSON file, either in the format of
+// aws ecs describe-task-definition or of aws ecs register-task-definition --cli-input-json
+func ReadTaskDefinition(path string) (ecs.TaskDefinition, error) {
+	var taskDefinition ecs.TaskDefinition
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return taskDefinition, err
+	}
+	// The fields of the SDK types match the keys of the JSON format of the API, ignoring case
+	var described struct {
+		TaskDefinition *ecs.TaskDefinition `json:"taskDefinition"`
+	}
+	if err := json.Unmarshal(content, &described); err == nil && described.TaskDefinition != nil {
+		taskDefinition = *described.TaskDefinition
+	} else if err := json.Unmarshal(content, &taskDefinition); err != nil {
+		return taskDefinition, fmt.Errorf("invalid task definition %s: %s", path, err.Error())
+	}
+	if taskDefinition.Family == nil {
+		return taskDefinition, fmt.Errorf("invalid task definition %s: family is missing", path)
+	}
+	for _, container := range taskDefinition.ContainerDefinitions {
+		if container.Name == nil || container.Image == nil {
+			return taskDefinition, fmt.Errorf("invalid task definition %s: containers require a name and an image", path)
+		}
+	}
+	return taskDefinition, nil
+}
@@ -0,0 +1,67 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+func TestLintImageTag(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"nginx:1.25", ""},
+		{"nginx", "image nginx has no tag and defaults to latest"},
+		{"nginx:latest", "image nginx:latest uses the latest tag"},
+		{"localhost:5000/app:1.0", ""},
+		{"localhost:5000/app", "image localhost:5000/app has no tag and defaults to latest"},
+		{"registry.acme.com:5000/team/app:latest", "image registry.acme.com:5000/team/app:latest uses the latest tag"},
+		{"app@sha256:4c0fdaa8b6341bfdeca5f18f7837462c80cff90527ee35ef185571e1c327beac", ""},
+		{"localhost:5000/app@sha256:4c0fdaa8b6341bfdeca5f18f7837462c80cff90527ee35ef185571e1c327beac", ""},
+	}
+	for _, test := range tests {
+		taskDefinition := ecs.TaskDefinition{
+			Family:               aws.String("app"),
+			ContainerDefinitions: []ecs.ContainerDefinition{{Name: aws.String("app"), Image: aws.String(test.image)}},
+		}
+		want := []Finding{}
+		if test.want != "" {
+			want = []Finding{{Rule: "image-tag", TaskDefinition: "app", Container: "app", Message: test.want}}
+		}
+		if got := LintTaskDefinition(taskDefinition, map[string]bool{"image-tag": true}); !reflect.DeepEqual(got, want) {
+			t.Errorf("image-tag on %s = %+v, want %+v", test.image, got, want)
+		}
+	}
+}
+
+func TestLintEssentialContainer(t *testing.T) {
+	tests := []struct {
+		name      string
+		essential []*bool
+		finding   bool
+	}{
+		{"essential by default", []*bool{nil}, false},
+		{"explicitly essential", []*bool{aws.Bool(true)}, false},
+		{"not essential", []*bool{aws.Bool(false)}, true},
+		{"one essential by default", []*bool{aws.Bool(false), nil}, false},
+		{"none essential", []*bool{aws.Bool(false), aws.Bool(false)}, true},
+	}
+	for _, test := range tests {
+		taskDefinition := ecs.TaskDefinition{Family: aws.String("app"), Revision: aws.Int64(3)}
+		for _, essential := range test.essential {
+			taskDefinition.ContainerDefinitions = append(taskDefinition.ContainerDefinitions, ecs.ContainerDefinition{
+				Name: aws.String("app"), Image: aws.String("app:1.0"), Essential: essential,
+			})
+		}
+		want := []Finding{}
+		if test.finding {
+			want = []Finding{{Rule: "essential-container", TaskDefinition: "app:3", Message: "no essential container"}}
+		}
+		if got := LintTaskDefinition(taskDefinition, map[string]bool{"essential-container": true}); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: essential-container = %+v, want %+v", test.name, got, want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/awserr"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+// LogStream is the CloudWatch Logs stream a container writes to with the awslogs driver
+type LogStream struct {
+	client    *cloudwatchlogs.Client
+	group     string
+	name      string
+	nextToken *string
+}
+
+// ContainerLogStream returns the log stream of a container of a task, or nil if the container
+// does not send its logs to CloudWatch Logs
+func ContainerLogStream(cfg aws.Config, container ecs.ContainerDefinition, taskArn string) *LogStream {
+	if container.LogConfiguration == nil || container.LogConfiguration.LogDriver != ecs.LogDriverAwslogs {
+		return nil
+	}
+	options := container.LogConfiguration.Options
+	if options["awslogs-stream-prefix"] == "" {
+		return nil
+	}
+	if options["awslogs-region"] != "" {
+		cfg = cfg.Copy()
+		cfg.Region = options["awslogs-region"]
+	}
+	return &LogStream{
+		client: cloudwatchlogs.New(cfg),
+		group:  options["awslogs-group"],
+		name: fmt.Sprintf(
+			"%s/%s/%s", options["awslogs-stream-prefix"], *container.Name, TaskIDFromArn(taskArn),
+		),
+	}
+}
+
+// PrintNewEvents prints the log events written since the last call
+func (s *LogStream) PrintNewEvents() {
+	for {
+		resp, err := s.client.GetLogEventsRequest(&cloudwatchlogs.GetLogEventsInput{
+			LogGroupName:  &s.group,
+			LogStreamName: &s.name,
+			NextToken:     s.nextToken,
+			StartFromHead: aws.Bool(true),
+		}).Send(context.Background())
+		if err != nil {
+			// The log stream is only created once the container has started
+			if aerr, ok := err.(awserr.Error); ok && aerr.Code() == cloudwatchlogs.ErrCodeResourceNotFoundException {
+				return
+			}
+			fmt.Println("Failed to get log events: " + err.Error())
+			os.Exit(1)
+		}
+		for _, event := range resp.Events {
+			timestamp := time.Unix(0, *event.Timestamp*int64(time.Millisecond))
+			fmt.Printf("%s %s\n", timestamp.Format(time.RFC3339), *event.Message)
+		}
+		if s.nextToken != nil && *s.nextToken == *resp.NextForwardToken {
+			return
+		}
+		s.nextToken = resp.NextForwardToken
+	}
+}
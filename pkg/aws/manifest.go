@@ -0,0 +1,201 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest describes the desired state of ECS services
+type Manifest struct {
+	Services []ServiceManifest `yaml:"services"`
+}
+
+// ServiceManifest describes the desired state of an ECS service, unset fields being left unchanged
+type ServiceManifest struct {
+	Cluster      string                       `yaml:"cluster"`
+	Service      string                       `yaml:"service"`
+	DesiredCount *int64                       `yaml:"desiredCount,omitempty"`
+	Containers   map[string]ContainerManifest `yaml:"containers,omitempty"`
+	AutoScaling  *AutoScalingManifest         `yaml:"autoscaling,omitempty"`
+}
+
+// ContainerManifest describes the desired state of a container of an ECS service. Only the
+// environment variables listed are managed, the other variables of the container are left unchanged
+type ContainerManifest struct {
+	Image       string            `yaml:"image,omitempty"`
+	Environment map[string]string `yaml:"environment,omitempty"`
+}
+
+// AutoScalingManifest describes the desired Service Auto Scaling bounds of an ECS service
+type AutoScalingManifest struct {
+	Min *int64 `yaml:"min,omitempty" json:"min,omitempty"`
+	Max *int64 `yaml:"max,omitempty" json:"max,omitempty"`
+}
+
+// LoadManifest reads a YAML manifest file
+func LoadManifest(path string) (Manifest, error) {
+	var manifest Manifest
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return manifest, err
+	}
+	if err := yaml.Unmarshal(content, &manifest); err != nil {
+		return manifest, fmt.Errorf("invalid manifest %s: %s", path, err.Error())
+	}
+	for index, service := range manifest.Services {
+		if service.Cluster == "" || service.Service == "" {
+			return manifest, fmt.Errorf("invalid manifest %s: service #%d requires a cluster and a service", path, index+1)
+		}
+	}
+	return manifest, nil
+}
+
+// Change is a difference between the live and the desired state of an ECS service
+type Change struct {
+	Path string
+	Old  *string
+	New  string
+	// Variable is the name of the environment variable changed, if any
+	Variable string
+}
+
+// ServicePlan holds the changes needed to bring an ECS service to the state described by its manifest
+type ServicePlan struct {
+	Manifest       ServiceManifest
+	Service        ecs.Service
+	TaskDefinition ecs.TaskDefinition
+	Changes        []Change
+
+	newTaskDefinition *ecs.TaskDefinition
+	tags              []ecs.Tag
+	desiredCount      *int64
+	minCapacity       *int64
+	maxCapacity       *int64
+}
+
+func formatInt(value int64) *string {
+	formatted := strconv.FormatInt(value, 10)
+	return &formatted
+}
+
+// PlanService compares an ECS service with its manifest
+func PlanService(client *ecs.Client, manifest ServiceManifest) (ServicePlan, error) {
+	plan := ServicePlan{Manifest: manifest}
+	service, err := FindService(client, manifest.Cluster, manifest.Service)
+	if err != nil {
+		return plan, err
+	}
+	plan.Service = service
+	plan.TaskDefinition, plan.tags = DescribeTaskDefinition(client, *service.TaskDefinition)
+
+	if manifest.DesiredCount != nil && *manifest.DesiredCount != *service.DesiredCount {
+		plan.desiredCount = manifest.DesiredCount
+		plan.Changes = append(plan.Changes, Change{
+			Path: "desiredCount", Old: formatInt(*service.DesiredCount), New: *formatInt(*manifest.DesiredCount),
+		})
+	}
+
+	taskDefinition := copyTaskDefinition(plan.TaskDefinition)
+	containerNames := make([]string, 0, len(manifest.Containers))
+	for name := range manifest.Containers {
+		containerNames = append(containerNames, name)
+	}
+	sort.Strings(containerNames)
+	for _, name := range containerNames {
+		desired := manifest.Containers[name]
+		var container *ecs.ContainerDefinition
+		for index := range taskDefinition.ContainerDefinitions {
+			if *taskDefinition.ContainerDefinitions[index].Name == name {
+				container = &taskDefinition.ContainerDefinitions[index]
+			}
+		}
+		if container == nil {
+			return plan, fmt.Errorf("container %s not found in task definition %s", name, shortTaskDefinitionName(*service.TaskDefinition))
+		}
+		if desired.Image != "" && desired.Image != *container.Image {
+			plan.Changes = append(plan.Changes, Change{
+				Path: fmt.Sprintf("containers.%s.image", name), Old: container.Image, New: desired.Image,
+			})
+			image := desired.Image
+			container.Image = &image
+			plan.newTaskDefinition = &taskDefinition
+		}
+		environment := ContainerEnvironment(container)
+		variables := make([]string, 0, len(desired.Environment))
+		for variable := range desired.Environment {
+			variables = append(variables, variable)
+		}
+		sort.Strings(variables)
+		for _, variable := range variables {
+			value := desired.Environment[variable]
+			current, ok := environment[variable]
+			if ok && current == value {
+				continue
+			}
+			change := Change{Path: fmt.Sprintf("containers.%s.environment.%s", name, variable), New: value, Variable: variable}
+			if ok {
+				change.Old = &current
+			}
+			plan.Changes = append(plan.Changes, change)
+			environment[variable] = value
+			plan.newTaskDefinition = &taskDefinition
+		}
+		SetContainerEnvironment(container, environment)
+	}
+
+	if manifest.AutoScaling != nil {
+		target := ScalableTarget(client, manifest.Cluster, manifest.Service)
+		if target == nil && (manifest.AutoScaling.Min == nil || manifest.AutoScaling.Max == nil) {
+			return plan, fmt.Errorf("service %s does not use Service Auto Scaling, both autoscaling.min and autoscaling.max are required", manifest.Service)
+		}
+		if min := manifest.AutoScaling.Min; min != nil && (target == nil || *target.MinCapacity != *min) {
+			change := Change{Path: "autoscaling.min", New: *formatInt(*min)}
+			if target != nil {
+				change.Old = formatInt(*target.MinCapacity)
+			}
+			plan.Changes = append(plan.Changes, change)
+			plan.minCapacity = min
+		}
+		if max := manifest.AutoScaling.Max; max != nil && (target == nil || *target.MaxCapacity != *max) {
+			change := Change{Path: "autoscaling.max", New: *formatInt(*max)}
+			if target != nil {
+				change.Old = formatInt(*target.MaxCapacity)
+			}
+			plan.Changes = append(plan.Changes, change)
+			plan.maxCapacity = max
+		}
+	}
+	return plan, nil
+}
+
+// Apply applies the changes of a plan, registering a new revision of the task definition of
+// the service when its containers change
+func (p ServicePlan) Apply(client *ecs.Client) error {
+	params := ecs.UpdateServiceInput{
+		Cluster:      &p.Manifest.Cluster,
+		Service:      &p.Manifest.Service,
+		DesiredCount: p.desiredCount,
+	}
+	if p.newTaskDefinition != nil {
+		taskDefinition, err := RegisterTaskDefinition(client, *p.newTaskDefinition, p.tags)
+		if err != nil {
+			return err
+		}
+		params.TaskDefinition = taskDefinition.TaskDefinitionArn
+	}
+	if params.DesiredCount != nil || params.TaskDefinition != nil {
+		if _, err := client.UpdateServiceRequest(&params).Send(context.Background()); err != nil {
+			return err
+		}
+	}
+	if p.minCapacity != nil || p.maxCapacity != nil {
+		RegisterScalableTarget(client, p.Manifest.Cluster, p.Manifest.Service, p.minCapacity, p.maxCapacity, nil)
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package aws
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+// PlacementInstance is a container instance considered when simulating the placement of tasks
+type PlacementInstance struct {
+	InstanceResources
+	ContainerInstanceArn string
+	// GroupTasks is the number of tasks of the simulated service already running on the instance
+	GroupTasks int64
+	// Placed is the number of tasks placed on the instance by the simulation
+	Placed int64
+	// TaskGroups holds the group of each task running on the instance, used by task:group expressions
+	TaskGroups []string
+
+	attributes     map[string]string
+	agentConnected bool
+	runningTasks   int64
+	reservedPorts  map[int64]bool
+}
+
+// NewPlacementInstance builds a PlacementInstance from a container instance
+func NewPlacementInstance(containerInstance *ecs.ContainerInstance) *PlacementInstance {
+	instance := &PlacementInstance{
+		InstanceResources:    ContainerInstanceResources(containerInstance),
+		ContainerInstanceArn: *containerInstance.ContainerInstanceArn,
+		attributes:           make(map[string]string),
+		agentConnected:       *containerInstance.AgentConnected,
+		runningTasks:         *containerInstance.RunningTasksCount,
+		reservedPorts:        make(map[int64]bool),
+	}
+	for _, attr := range containerInstance.Attributes {
+		value := ""
+		if attr.Value != nil {
+			value = *attr.Value
+		}
+		instance.attributes[*attr.Name] = value
+	}
+	for _, port := range FindResource(containerInstance.RemainingResources, "PORTS").StringSetValue {
+		if value, err := strconv.ParseInt(port, 10, 64); err == nil {
+			instance.reservedPorts[value] = true
+		}
+	}
+	return instance
+}
+
+// AvailabilityZone returns the availability zone of the instance
+func (i *PlacementInstance) AvailabilityZone() string {
+	return i.attributes["ecs.availability-zone"]
+}
+
+func (i *PlacementInstance) attribute(name string) (string, bool) {
+	switch name {
+	case "ec2InstanceId", "instanceId":
+		return i.InstanceID, true
+	case "agentConnected":
+		return strconv.FormatBool(i.agentConnected), true
+	case "runningTasksCount":
+		return strconv.FormatInt(i.runningTasks+i.Placed, 10), true
+	}
+	value, ok := i.attributes[strings.TrimPrefix(name, "attribute:")]
+	return value, ok
+}
+
+// attributeValues returns the values of a subject of the cluster query language for the instance,
+// task:group having a value for each task running on it
+func (i *PlacementInstance) attributeValues(name string) []string {
+	if name == "task:group" {
+		return i.TaskGroups
+	}
+	if value, ok := i.attribute(name); ok {
+		return []string{value}
+	}
+	return nil
+}
+
+// Placement describes the tasks whose placement is simulated
+type Placement struct {
+	// Group is the task group of the placed tasks, e.g. service:NAME
+	Group       string
+	CPU         int64
+	Memory      int64
+	HostPorts   []int64
+	Constraints []ecs.PlacementConstraint
+	Strategies  []ecs.PlacementStrategy
+}
+
+// TaskDefinitionHostPorts returns the ports a task definition reserves on its container instance
+func TaskDefinitionHostPorts(taskDefinition *ecs.TaskDefinition) []int64 {
+	ports := make([]int64, 0)
+	if taskDefinition.NetworkMode == ecs.NetworkModeAwsvpc {
+		return ports
+	}
+	for _, container := range taskDefinition.ContainerDefinitions {
+		for _, mapping := range container.PortMappings {
+			if taskDefinition.NetworkMode == ecs.NetworkModeHost && mapping.ContainerPort != nil {
+				ports = append(ports, *mapping.ContainerPort)
+			} else if mapping.HostPort != nil && *mapping.HostPort != 0 {
+				ports = append(ports, *mapping.HostPort)
+			}
+		}
+	}
+	return ports
+}
+
+// Simulate places count tasks on the instances, one at a time, following the constraints and
+// strategies of the placement. It returns the number of tasks placed and, if some tasks could not
+// be placed, the reasons why the instances were rejected.
+func (p Placement) Simulate(instances []*PlacementInstance, count int) (int, []string, error) {
+	var (
+		distinctInstance bool
+		matchers         []instanceMatcher
+		expressions      []string
+	)
+	for _, constraint := range p.Constraints {
+		switch constraint.Type {
+		case ecs.PlacementConstraintTypeDistinctInstance:
+			distinctInstance = true
+		case ecs.PlacementConstraintTypeMemberOf:
+			matcher, err := parseExpression(*constraint.Expression)
+			if err != nil {
+				return 0, nil, fmt.Errorf("invalid memberOf expression %q: %s", *constraint.Expression, err.Error())
+			}
+			matchers = append(matchers, matcher)
+			expressions = append(expressions, *constraint.Expression)
+		}
+	}
+
+	for placed := 0; placed < count; placed++ {
+		rejections := make(map[string]int)
+		candidates := make([]*PlacementInstance, 0)
+		for _, instance := range instances {
+			if reason := p.reject(instance, distinctInstance, matchers, expressions); reason != "" {
+				rejections[reason]++
+				continue
+			}
+			candidates = append(candidates, instance)
+		}
+		if len(candidates) == 0 {
+			reasons := make([]string, 0)
+			for reason, n := range rejections {
+				reasons = append(reasons, fmt.Sprintf("%d instances %s", n, reason))
+			}
+			sort.Strings(reasons)
+			return placed, reasons, nil
+		}
+
+		spreadCounts := p.spreadCounts(instances)
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return p.prefer(candidates[i], candidates[j], spreadCounts)
+		})
+		chosen := candidates[0]
+		chosen.RemainingCPU -= p.CPU
+		chosen.RemainingMemory -= p.Memory
+		for _, port := range p.HostPorts {
+			chosen.reservedPorts[port] = true
+		}
+		chosen.GroupTasks++
+		chosen.Placed++
+		if p.Group != "" {
+			chosen.TaskGroups = append(chosen.TaskGroups, p.Group)
+		}
+	}
+	return count, nil, nil
+}
+
+// reject returns the reason why a task cannot be placed on an instance, or an empty string
+func (p Placement) reject(instance *PlacementInstance, distinctInstance bool, matchers []instanceMatcher, expressions []string) string {
+	if !instance.Active {
+		return "are not ACTIVE"
+	}
+	if instance.RemainingCPU < p.CPU {
+		return "do not have enough CPU"
+	}
+	if instance.RemainingMemory < p.Memory {
+		return "do not have enough memory"
+	}
+	for _, port := range p.HostPorts {
+		if instance.reservedPorts[port] {
+			return fmt.Sprintf("already use port %d", port)
+		}
+	}
+	if distinctInstance && instance.GroupTasks > 0 {
+		return "already run a task of the service (distinctInstance)"
+	}
+	for index, matcher := range matchers {
+		if !matcher(instance) {
+			return fmt.Sprintf("do not match memberOf(%s)", expressions[index])
+		}
+	}
+	return ""
+}
+
+// spreadCounts counts the tasks of the group for each value of the fields used by spread strategies
+func (p Placement) spreadCounts(instances []*PlacementInstance) map[string]map[string]int64 {
+	counts := make(map[string]map[string]int64)
+	for _, strategy := range p.Strategies {
+		if strategy.Type != ecs.PlacementStrategyTypeSpread || strategy.Field == nil {
+			continue
+		}
+		counts[*strategy.Field] = make(map[string]int64)
+		for _, instance := range instances {
+			value, _ := instance.attribute(*strategy.Field)
+			counts[*strategy.Field][value] += instance.GroupTasks
+		}
+	}
+	return counts
+}
+
+// prefer tells whether instance a is preferred over instance b by the placement strategies
+func (p Placement) prefer(a, b *PlacementInstance, spreadCounts map[string]map[string]int64) bool {
+	for _, strategy := range p.Strategies {
+		field := ""
+		if strategy.Field != nil {
+			field = strings.ToLower(*strategy.Field)
+		}
+		switch strategy.Type {
+		case ecs.PlacementStrategyTypeSpread:
+			if strategy.Field == nil {
+				continue
+			}
+			valueA, _ := a.attribute(*strategy.Field)
+			valueB, _ := b.attribute(*strategy.Field)
+			countA, countB := spreadCounts[*strategy.Field][valueA], spreadCounts[*strategy.Field][valueB]
+			if countA != countB {
+				return countA < countB
+			}
+		case ecs.PlacementStrategyTypeBinpack:
+			if field == "cpu" && a.RemainingCPU != b.RemainingCPU {
+				return a.RemainingCPU < b.RemainingCPU
+			}
+			if field == "memory" && a.RemainingMemory != b.RemainingMemory {
+				return a.RemainingMemory < b.RemainingMemory
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,210 @@
+package aws
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"gopkg.in/yaml.v3"
+)
+
+// policyStringFields lists the fields of a service compared with regular expressions, tags being
+// available as tag:KEY
+var policyStringFields = map[string]bool{
+	"cluster": true, "service": true, "launchType": true, "schedulingStrategy": true,
+	"networkMode": true, "image": true, "taskRole": true, "family": true,
+}
+
+// policyNumberFields lists the fields of a service compared with numeric conditions
+var policyNumberFields = map[string]bool{
+	"desiredCount": true, "runningCount": true, "loadBalancers": true, "containers": true,
+}
+
+var policyNumberRegexp = regexp.MustCompile(`^\s*(==|!=|>=|<=|>|<)?\s*(-?[0-9]+(?:\.[0-9]+)?)\s*$`)
+
+// Policy is a set of rules that ECS services must follow
+type Policy struct {
+	Rules []PolicyRule `yaml:"rules"`
+}
+
+// PolicyRule requires the services matching some conditions to satisfy other conditions. String
+// conditions are regular expressions, numeric conditions are comparisons like ">= 2"
+type PolicyRule struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	Match       map[string]string `yaml:"match"`
+	Require     map[string]string `yaml:"require"`
+
+	match   []policyCondition
+	require []policyCondition
+}
+
+// Violation is a service not satisfying a rule of a policy
+type Violation struct {
+	Rule    string `json:"rule"`
+	Cluster string `json:"cluster"`
+	Service string `json:"service"`
+	Message string `json:"message"`
+}
+
+type policyCondition struct {
+	field    string
+	raw      string
+	pattern  *regexp.Regexp
+	operator string
+	number   float64
+}
+
+func compilePolicyConditions(conditions map[string]string) ([]policyCondition, error) {
+	fields := make([]string, 0, len(conditions))
+	for field := range conditions {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	compiled := make([]policyCondition, 0, len(fields))
+	for _, field := range fields {
+		condition := policyCondition{field: field, raw: conditions[field]}
+		switch {
+		case policyNumberFields[field]:
+			groups := policyNumberRegexp.FindStringSubmatch(condition.raw)
+			if groups == nil {
+				return nil, fmt.Errorf("invalid condition %q on %s, expected a comparison like >= 2", condition.raw, field)
+			}
+			condition.operator = groups[1]
+			if condition.operator == "" {
+				condition.operator = "=="
+			}
+			condition.number, _ = strconv.ParseFloat(groups[2], 64)
+		case policyStringFields[field] || strings.HasPrefix(field, "tag:"):
+			pattern, err := regexp.Compile(condition.raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regular expression %q on %s: %s", condition.raw, field, err.Error())
+			}
+			condition.pattern = pattern
+		default:
+			return nil, fmt.Errorf("unknown field %s", field)
+		}
+		compiled = append(compiled, condition)
+	}
+	return compiled, nil
+}
+
+// LoadPolicy reads a YAML policy file
+func LoadPolicy(path string) (Policy, error) {
+	var policy Policy
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return policy, err
+	}
+	if err := yaml.Unmarshal(content, &policy); err != nil {
+		return policy, fmt.Errorf("invalid policy %s: %s", path, err.Error())
+	}
+	for index := range policy.Rules {
+		rule := &policy.Rules[index]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", index+1)
+		}
+		if len(rule.Require) == 0 {
+			return policy, fmt.Errorf("invalid policy %s: rule %s has no require conditions", path, rule.Name)
+		}
+		if rule.match, err = compilePolicyConditions(rule.Match); err != nil {
+			return policy, fmt.Errorf("invalid policy %s: rule %s: %s", path, rule.Name, err.Error())
+		}
+		if rule.require, err = compilePolicyConditions(rule.Require); err != nil {
+			return policy, fmt.Errorf("invalid policy %s: rule %s: %s", path, rule.Name, err.Error())
+		}
+	}
+	return policy, nil
+}
+
+// serviceFacts returns the values of the fields of a service checked by policies
+func serviceFacts(clusterName string, service *ecs.Service, taskDefinition *ecs.TaskDefinition) map[string][]string {
+	facts := map[string][]string{
+		"cluster":            {clusterName},
+		"service":            {*service.ServiceName},
+		"launchType":         {string(service.LaunchType)},
+		"schedulingStrategy": {string(service.SchedulingStrategy)},
+		"networkMode":        {string(taskDefinition.NetworkMode)},
+		"family":             {*taskDefinition.Family},
+		"desiredCount":       {strconv.FormatInt(*service.DesiredCount, 10)},
+		"runningCount":       {strconv.FormatInt(*service.RunningCount, 10)},
+		"loadBalancers":      {strconv.Itoa(len(service.LoadBalancers))},
+		"containers":         {strconv.Itoa(len(taskDefinition.ContainerDefinitions))},
+	}
+	if taskDefinition.TaskRoleArn != nil {
+		facts["taskRole"] = []string{*taskDefinition.TaskRoleArn}
+	}
+	for _, container := range taskDefinition.ContainerDefinitions {
+		facts["image"] = append(facts["image"], *container.Image)
+	}
+	for _, tag := range service.Tags {
+		facts["tag:"+*tag.Key] = []string{*tag.Value}
+	}
+	return facts
+}
+
+// evaluate checks a condition against the values of a field, all the values having to satisfy
+// it. It returns an explanation when the condition is not satisfied
+func (c policyCondition) evaluate(values []string) (bool, string) {
+	if len(values) == 0 {
+		return false, fmt.Sprintf("%s is not set", c.field)
+	}
+	for _, value := range values {
+		if c.pattern != nil {
+			if !c.pattern.MatchString(value) {
+				return false, fmt.Sprintf("%s %q does not match %q", c.field, value, c.raw)
+			}
+			continue
+		}
+		number, _ := strconv.ParseFloat(value, 64)
+		var ok bool
+		switch c.operator {
+		case "==":
+			ok = number == c.number
+		case "!=":
+			ok = number != c.number
+		case ">=":
+			ok = number >= c.number
+		case "<=":
+			ok = number <= c.number
+		case ">":
+			ok = number > c.number
+		case "<":
+			ok = number < c.number
+		}
+		if !ok {
+			return false, fmt.Sprintf("%s is %s, expected %s %s", c.field, value, c.operator, strconv.FormatFloat(c.number, 'f', -1, 64))
+		}
+	}
+	return true, ""
+}
+
+// Check evaluates the rules of a policy against an ECS service and its task definition
+func (p Policy) Check(clusterName string, service *ecs.Service, taskDefinition *ecs.TaskDefinition) []Violation {
+	facts := serviceFacts(clusterName, service, taskDefinition)
+	violations := make([]Violation, 0)
+	for _, rule := range p.Rules {
+		matched := true
+		for _, condition := range rule.match {
+			if ok, _ := condition.evaluate(facts[condition.field]); !ok {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		for _, condition := range rule.require {
+			if ok, message := condition.evaluate(facts[condition.field]); !ok {
+				violations = append(violations, Violation{
+					Rule: rule.Name, Cluster: clusterName, Service: *service.ServiceName, Message: message,
+				})
+			}
+		}
+	}
+	return violations
+}
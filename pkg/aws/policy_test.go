@@ -0,0 +1,150 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+func TestCompilePolicyConditions(t *testing.T) {
+	tests := []struct {
+		value    string
+		operator string
+		number   float64
+	}{
+		{"2", "==", 2},
+		{"== 2", "==", 2},
+		{">= 2", ">=", 2},
+		{"<=10", "<=", 10},
+		{" > 1.5 ", ">", 1.5},
+		{"< 3", "<", 3},
+		{"!= 0", "!=", 0},
+		{">-1", ">", -1},
+	}
+	for _, test := range tests {
+		conditions, err := compilePolicyConditions(map[string]string{"desiredCount": test.value})
+		if err != nil {
+			t.Errorf("compilePolicyConditions(%q) failed: %s", test.value, err)
+			continue
+		}
+		if got := conditions[0]; got.operator != test.operator || got.number != test.number {
+			t.Errorf("compilePolicyConditions(%q) = %s %v, want %s %v", test.value, got.operator, got.number, test.operator, test.number)
+		}
+	}
+
+	conditions, err := compilePolicyConditions(map[string]string{"tag:team": "^payments$", "image": `\.dkr\.ecr\.`})
+	if err != nil {
+		t.Fatalf("compilePolicyConditions failed: %s", err)
+	}
+	if fields := []string{conditions[0].field, conditions[1].field}; !reflect.DeepEqual(fields, []string{"image", "tag:team"}) {
+		t.Errorf("compilePolicyConditions fields = %v, want them sorted", fields)
+	}
+	if conditions[1].pattern == nil {
+		t.Errorf("compilePolicyConditions did not compile the regular expression of tag:team")
+	}
+
+	for _, invalid := range []map[string]string{
+		{"desiredCount": "=> 2"},
+		{"desiredCount": "two"},
+		{"runningCount": ">= 1 and <= 3"},
+		{"image": "("},
+		{"tag:team": "[a-"},
+		{"memory": "512"},
+	} {
+		if _, err := compilePolicyConditions(invalid); err == nil {
+			t.Errorf("compilePolicyConditions(%v) succeeded, want an error", invalid)
+		}
+	}
+}
+
+func TestPolicyCheck(t *testing.T) {
+	service := ecs.Service{
+		ServiceName:  aws.String("api"),
+		DesiredCount: aws.Int64(1),
+		RunningCount: aws.Int64(1),
+		LaunchType:   ecs.LaunchTypeFargate,
+		Tags:         []ecs.Tag{{Key: aws.String("env"), Value: aws.String("prod")}},
+	}
+	taskDefinition := ecs.TaskDefinition{
+		Family:      aws.String("api"),
+		NetworkMode: ecs.NetworkModeAwsvpc,
+		ContainerDefinitions: []ecs.ContainerDefinition{
+			{Name: aws.String("api"), Image: aws.String("123456789012.dkr.ecr.us-east-1.amazonaws.com/api:1.0")},
+			{Name: aws.String("proxy"), Image: aws.String("envoyproxy/envoy:v1.27")},
+		},
+	}
+	tests := []struct {
+		name     string
+		match    map[string]string
+		require  map[string]string
+		messages []string
+	}{
+		{
+			name:    "satisfied",
+			match:   map[string]string{"tag:env": "^prod$"},
+			require: map[string]string{"launchType": "FARGATE", "containers": "<= 2"},
+		},
+		{
+			name:     "numeric condition on a matched tag",
+			match:    map[string]string{"tag:env": "^prod$"},
+			require:  map[string]string{"desiredCount": ">= 2"},
+			messages: []string{"desiredCount is 1, expected >= 2"},
+		},
+		{
+			name:    "tag not matching",
+			match:   map[string]string{"tag:env": "^staging$"},
+			require: map[string]string{"desiredCount": ">= 2"},
+		},
+		{
+			name:    "missing tag in match",
+			match:   map[string]string{"tag:team": "."},
+			require: map[string]string{"desiredCount": ">= 2"},
+		},
+		{
+			name:     "missing tag in require",
+			require:  map[string]string{"tag:team": "."},
+			messages: []string{"tag:team is not set"},
+		},
+		{
+			name:     "every image must match",
+			require:  map[string]string{"image": `^[0-9]{12}\.dkr\.ecr\.`},
+			messages: []string{`image "envoyproxy/envoy:v1.27" does not match "^[0-9]{12}\\.dkr\\.ecr\\."`},
+		},
+		{
+			name:    "images all matching",
+			require: map[string]string{"image": `:v?[0-9.]+$`},
+		},
+		{
+			name:     "missing task role",
+			require:  map[string]string{"taskRole": "."},
+			messages: []string{"taskRole is not set"},
+		},
+		{
+			name:     "several violations",
+			require:  map[string]string{"networkMode": "bridge", "loadBalancers": ">= 1"},
+			messages: []string{"loadBalancers is 0, expected >= 1", `networkMode "awsvpc" does not match "bridge"`},
+		},
+	}
+	for _, test := range tests {
+		rule := PolicyRule{Name: test.name, Match: test.match, Require: test.require}
+		var err error
+		if rule.match, err = compilePolicyConditions(rule.Match); err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		if rule.require, err = compilePolicyConditions(rule.Require); err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		messages := make([]string, 0)
+		for _, violation := range (Policy{Rules: []PolicyRule{rule}}).Check("prod", &service, &taskDefinition) {
+			if violation.Rule != test.name || violation.Cluster != "prod" || violation.Service != "api" {
+				t.Errorf("%s: unexpected violation %+v", test.name, violation)
+			}
+			messages = append(messages, violation.Message)
+		}
+		if len(messages) != len(test.messages) || (len(messages) > 0 && !reflect.DeepEqual(messages, test.messages)) {
+			t.Errorf("%s: violations %q, want %q", test.name, messages, test.messages)
+		}
+	}
+}
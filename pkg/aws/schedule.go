@@ -0,0 +1,242 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/applicationautoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+var cronDaysOfWeek = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+
+var cronMonths = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+
+// CronSchedule converts a standard 5-field cron expression (minute hour day-of-month month
+// day-of-week) to an Application Auto Scaling schedule expression
+func CronSchedule(cron string) (string, error) {
+	fields := strings.Fields(cron)
+	if len(fields) != 5 {
+		return "", fmt.Errorf("invalid cron expression %q, expected 5 fields", cron)
+	}
+	dayOfMonth, dayOfWeek, month := fields[2], strings.ToUpper(fields[4]), strings.ToUpper(fields[3])
+	// Schedule expressions require exactly one of day-of-month and day-of-week to be ?
+	if dayOfWeek == "*" || dayOfWeek == "?" {
+		dayOfWeek = "?"
+		if dayOfMonth == "?" {
+			dayOfMonth = "*"
+		}
+	} else {
+		if dayOfMonth != "*" && dayOfMonth != "?" {
+			return "", fmt.Errorf("invalid cron expression %q, day-of-month and day-of-week cannot both be set", cron)
+		}
+		dayOfMonth = "?"
+		// Standard cron numbers days of week from 0 (Sunday), use names to avoid ambiguity
+		var converted strings.Builder
+		number := ""
+		flush := func(next string) error {
+			if number == "" {
+				return nil
+			}
+			n, err := strconv.Atoi(number)
+			if err != nil || n > 7 {
+				return fmt.Errorf("invalid day of week %q", number)
+			}
+			number = ""
+			// Sunday is both 0 and 7, a range ending with 7 like 5-7 ends with Sunday
+			if n == 7 && strings.HasSuffix(converted.String(), "-") {
+				if next == "/" {
+					return fmt.Errorf("invalid day of week range ending with 7 in %q", dayOfWeek)
+				}
+				converted.WriteString("SAT,SUN")
+				return nil
+			}
+			converted.WriteString(cronDaysOfWeek[n%7])
+			return nil
+		}
+		for i, r := range dayOfWeek {
+			if r >= '0' && r <= '9' && (i == 0 || dayOfWeek[i-1] != '/') {
+				number += string(r)
+				continue
+			}
+			if err := flush(string(r)); err != nil {
+				return "", err
+			}
+			converted.WriteRune(r)
+		}
+		if err := flush(""); err != nil {
+			return "", err
+		}
+		dayOfWeek = converted.String()
+	}
+	return fmt.Sprintf("cron(%s %s %s %s %s *)", fields[0], fields[1], dayOfMonth, month, dayOfWeek), nil
+}
+
+// NextRun returns the next time a schedule expression (at or cron) runs after a given time, in UTC
+func NextRun(schedule string, after time.Time) (time.Time, bool) {
+	after = after.UTC()
+	if strings.HasPrefix(schedule, "at(") {
+		at, err := time.Parse("2006-01-02T15:04:05", strings.TrimSuffix(strings.TrimPrefix(schedule, "at("), ")"))
+		if err != nil || at.Before(after) {
+			return time.Time{}, false
+		}
+		return at, true
+	}
+	if !strings.HasPrefix(schedule, "cron(") {
+		return time.Time{}, false
+	}
+	fields := strings.Fields(strings.TrimSuffix(strings.TrimPrefix(schedule, "cron("), ")"))
+	if len(fields) != 6 {
+		return time.Time{}, false
+	}
+	minutes, err1 := cronField(fields[0], 0, 59, nil)
+	hours, err2 := cronField(fields[1], 0, 23, nil)
+	daysOfMonth, err3 := cronField(fields[2], 1, 31, nil)
+	months, err4 := cronField(fields[3], 1, 12, cronMonths)
+	daysOfWeek, err5 := cronField(fields[4], 1, 7, cronDaysOfWeek)
+	years, err6 := cronField(fields[5], 1970, 2199, nil)
+	for _, err := range []error{err1, err2, err3, err4, err5, err6} {
+		if err != nil {
+			return time.Time{}, false
+		}
+	}
+
+	day := time.Date(after.Year(), after.Month(), after.Day(), 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 366*5; i++ {
+		if years[day.Year()] && months[int(day.Month())] && daysOfMonth[day.Day()] && daysOfWeek[int(day.Weekday())+1] {
+			for hour := 0; hour < 24; hour++ {
+				for minute := 0; minute < 60; minute++ {
+					if !hours[hour] || !minutes[minute] {
+						continue
+					}
+					run := day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+					if run.After(after) {
+						return run, true
+					}
+				}
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}, false
+}
+
+// cronField returns the values matched by a field of a cron expression, names being the
+// names of the values starting from min
+func cronField(field string, min, max int, names []string) (map[int]bool, error) {
+	values := make(map[int]bool)
+	parseValue := func(value string) (int, error) {
+		for index, name := range names {
+			if strings.ToUpper(value) == name {
+				return min + index, nil
+			}
+		}
+		return strconv.Atoi(value)
+	}
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if index := strings.Index(part, "/"); index >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[index+1:]); err != nil || step < 1 {
+				return nil, fmt.Errorf("invalid step in %q", part)
+			}
+			part = part[:index]
+		}
+		low, high := min, max
+		switch {
+		case part == "*" || part == "?":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if low, err = parseValue(bounds[0]); err != nil {
+				return nil, err
+			}
+			if high, err = parseValue(bounds[1]); err != nil {
+				return nil, err
+			}
+		default:
+			value, err := parseValue(part)
+			if err != nil {
+				return nil, err
+			}
+			low = value
+			if step == 1 {
+				high = value
+			}
+		}
+		if low < min || high > max || low > high {
+			return nil, fmt.Errorf("invalid range in %q, values go from %d to %d", field, min, max)
+		}
+		for value := low; value <= high; value += step {
+			values[value] = true
+		}
+	}
+	return values, nil
+}
+
+// PutScheduledAction creates or updates a scheduled action changing the capacity of an ECS service
+func PutScheduledAction(client *ecs.Client, clusterName, serviceName, name, schedule string, minCapacity, maxCapacity *int64) {
+	aasClient := applicationautoscaling.New(client.Config)
+	resourceID := serviceResourceID(clusterName, serviceName)
+	_, err := aasClient.PutScheduledActionRequest(&applicationautoscaling.PutScheduledActionInput{
+		ServiceNamespace:    applicationautoscaling.ServiceNamespaceEcs,
+		ScalableDimension:   applicationautoscaling.ScalableDimensionEcsServiceDesiredCount,
+		ResourceId:          &resourceID,
+		ScheduledActionName: &name,
+		Schedule:            &schedule,
+		ScalableTargetAction: &applicationautoscaling.ScalableTargetAction{
+			MinCapacity: minCapacity,
+			MaxCapacity: maxCapacity,
+		},
+	}).Send(context.Background())
+	if err != nil {
+		fmt.Println("Failed to put scheduled action: " + err.Error())
+		os.Exit(1)
+	}
+}
+
+// DeleteScheduledAction deletes a scheduled action of an ECS service
+func DeleteScheduledAction(client *ecs.Client, clusterName, serviceName, name string) {
+	aasClient := applicationautoscaling.New(client.Config)
+	resourceID := serviceResourceID(clusterName, serviceName)
+	_, err := aasClient.DeleteScheduledActionRequest(&applicationautoscaling.DeleteScheduledActionInput{
+		ServiceNamespace:    applicationautoscaling.ServiceNamespaceEcs,
+		ScalableDimension:   applicationautoscaling.ScalableDimensionEcsServiceDesiredCount,
+		ResourceId:          &resourceID,
+		ScheduledActionName: &name,
+	}).Send(context.Background())
+	if err != nil {
+		fmt.Println("Failed to delete scheduled action: " + err.Error())
+		os.Exit(1)
+	}
+}
+
+// ScheduledActions returns the scheduled actions of the ECS services of a cluster, by service name
+func ScheduledActions(client *ecs.Client, clusterName string) map[string][]applicationautoscaling.ScheduledAction {
+	aasClient := applicationautoscaling.New(client.Config)
+	req := aasClient.DescribeScheduledActionsRequest(&applicationautoscaling.DescribeScheduledActionsInput{
+		ServiceNamespace:  applicationautoscaling.ServiceNamespaceEcs,
+		ScalableDimension: applicationautoscaling.ScalableDimensionEcsServiceDesiredCount,
+	})
+	p := applicationautoscaling.NewDescribeScheduledActionsPaginator(req)
+
+	prefix := serviceResourceID(clusterName, "")
+	actions := make(map[string][]applicationautoscaling.ScheduledAction)
+	for p.Next(context.Background()) {
+		for _, action := range p.CurrentPage().ScheduledActions {
+			if strings.HasPrefix(*action.ResourceId, prefix) {
+				serviceName := strings.TrimPrefix(*action.ResourceId, prefix)
+				actions[serviceName] = append(actions[serviceName], action)
+			}
+		}
+	}
+	if err := p.Err(); err != nil {
+		fmt.Println("Failed to describe scheduled actions: " + err.Error())
+		os.Exit(1)
+	}
+	return actions
+}
@@ -0,0 +1,165 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCronSchedule(t *testing.T) {
+	tests := []struct {
+		cron string
+		want string
+	}{
+		{"0 8 * * *", "cron(0 8 * * ? *)"},
+		{"30 18 1 * *", "cron(30 18 1 * ? *)"},
+		{"0 8 ? * *", "cron(0 8 * * ? *)"},
+		{"0 8 15 * ?", "cron(0 8 15 * ? *)"},
+		{"0 8 * * 1-5", "cron(0 8 ? * MON-FRI *)"},
+		{"0 8 ? * 1-5", "cron(0 8 ? * MON-FRI *)"},
+		{"0 8 * * MON-FRI", "cron(0 8 ? * MON-FRI *)"},
+		{"0 8 * * mon,wed,fri", "cron(0 8 ? * MON,WED,FRI *)"},
+		{"0 8 * * 0", "cron(0 8 ? * SUN *)"},
+		{"0 8 * * 7", "cron(0 8 ? * SUN *)"},
+		{"0 8 * * 0,6", "cron(0 8 ? * SUN,SAT *)"},
+		{"0 8 * * 5-7", "cron(0 8 ? * FRI-SAT,SUN *)"},
+		{"0 8 * * 1-5/2", "cron(0 8 ? * MON-FRI/2 *)"},
+		{"0 8 * * */2", "cron(0 8 ? * */2 *)"},
+		{"0 8 * jan-mar 1", "cron(0 8 ? JAN-MAR MON *)"},
+	}
+	for _, test := range tests {
+		got, err := CronSchedule(test.cron)
+		if err != nil {
+			t.Errorf("CronSchedule(%q) failed: %s", test.cron, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("CronSchedule(%q) = %q, want %q", test.cron, got, test.want)
+		}
+	}
+}
+
+func TestCronScheduleErrors(t *testing.T) {
+	for _, cron := range []string{
+		"0 8 * *",
+		"0 8 * * * *",
+		"0 8 1 * 1",
+		"0 8 * * 8",
+		"0 8 * * 5-7/2",
+	} {
+		if got, err := CronSchedule(cron); err == nil {
+			t.Errorf("CronSchedule(%q) = %q, want an error", cron, got)
+		}
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	// 2024-01-03 is a Wednesday
+	after := time.Date(2024, 1, 3, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		schedule string
+		want     time.Time
+		ok       bool
+	}{
+		{"cron(0 8 * * ? *)", time.Date(2024, 1, 4, 8, 0, 0, 0, time.UTC), true},
+		{"cron(45 10 * * ? *)", time.Date(2024, 1, 3, 10, 45, 0, 0, time.UTC), true},
+		{"cron(30 10 * * ? *)", time.Date(2024, 1, 4, 10, 30, 0, 0, time.UTC), true},
+		{"cron(0/20 * * * ? *)", time.Date(2024, 1, 3, 10, 40, 0, 0, time.UTC), true},
+		{"cron(0 8 15 * ? *)", time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC), true},
+		{"cron(0 8 ? * MON-FRI *)", time.Date(2024, 1, 4, 8, 0, 0, 0, time.UTC), true},
+		{"cron(0 8 ? * SAT,SUN *)", time.Date(2024, 1, 6, 8, 0, 0, 0, time.UTC), true},
+		{"cron(0 8 ? * SUN *)", time.Date(2024, 1, 7, 8, 0, 0, 0, time.UTC), true},
+		// Schedule expressions number days of week from 1 (Sunday)
+		{"cron(0 8 ? * 1 *)", time.Date(2024, 1, 7, 8, 0, 0, 0, time.UTC), true},
+		{"cron(0 8 ? * 2-6 *)", time.Date(2024, 1, 4, 8, 0, 0, 0, time.UTC), true},
+		{"cron(0 8 ? * 7 *)", time.Date(2024, 1, 6, 8, 0, 0, 0, time.UTC), true},
+		{"cron(0 0 1 MAR ? *)", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), true},
+		{"cron(0 0 29 2 ? *)", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), true},
+		{"cron(0 0 1 1 ? 2025)", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), true},
+		{"cron(0 0 1 1 ? 2023)", time.Time{}, false},
+		{"at(2024-02-01T12:00:00)", time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC), true},
+		{"at(2023-12-31T12:00:00)", time.Time{}, false},
+		{"rate(5 minutes)", time.Time{}, false},
+		{"cron(0 8 * * ?)", time.Time{}, false},
+		{"cron(0 25 * * ? *)", time.Time{}, false},
+		{"cron(0 8 ? * 2#1 *)", time.Time{}, false},
+	}
+	for _, test := range tests {
+		got, ok := NextRun(test.schedule, after)
+		if ok != test.ok || !got.Equal(test.want) {
+			t.Errorf("NextRun(%q) = %s, %v, want %s, %v", test.schedule, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestCronSchedulesNextRun(t *testing.T) {
+	// Converted cron expressions run on the same days as the standard ones, Sunday being 0 or 7
+	after := time.Date(2024, 1, 3, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		cron string
+		want time.Time
+	}{
+		{"0 8 * * 0", time.Date(2024, 1, 7, 8, 0, 0, 0, time.UTC)},
+		{"0 8 * * 7", time.Date(2024, 1, 7, 8, 0, 0, 0, time.UTC)},
+		{"0 8 * * 1", time.Date(2024, 1, 8, 8, 0, 0, 0, time.UTC)},
+		{"0 8 * * 6-7", time.Date(2024, 1, 6, 8, 0, 0, 0, time.UTC)},
+		{"0 8 ? * *", time.Date(2024, 1, 4, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		schedule, err := CronSchedule(test.cron)
+		if err != nil {
+			t.Errorf("CronSchedule(%q) failed: %s", test.cron, err)
+			continue
+		}
+		if got, ok := NextRun(schedule, after); !ok || !got.Equal(test.want) {
+			t.Errorf("NextRun(%q) = %s, %v, want %s", schedule, got, ok, test.want)
+		}
+	}
+}
+
+func TestCronField(t *testing.T) {
+	tests := []struct {
+		field    string
+		min, max int
+		names    []string
+		want     []int
+	}{
+		{"5", 0, 59, nil, []int{5}},
+		{"1,15,30", 0, 59, nil, []int{1, 15, 30}},
+		{"10-12", 0, 23, nil, []int{10, 11, 12}},
+		{"*/15", 0, 59, nil, []int{0, 15, 30, 45}},
+		{"50/5", 0, 59, nil, []int{50, 55}},
+		{"1-10/3", 1, 31, nil, []int{1, 4, 7, 10}},
+		{"?", 1, 7, cronDaysOfWeek, []int{1, 2, 3, 4, 5, 6, 7}},
+		{"SUN", 1, 7, cronDaysOfWeek, []int{1}},
+		{"mon-fri", 1, 7, cronDaysOfWeek, []int{2, 3, 4, 5, 6}},
+		{"SAT,SUN", 1, 7, cronDaysOfWeek, []int{1, 7}},
+		{"1", 1, 7, cronDaysOfWeek, []int{1}},
+		{"JAN,DEC", 1, 12, cronMonths, []int{1, 12}},
+	}
+	for _, test := range tests {
+		got, err := cronField(test.field, test.min, test.max, test.names)
+		if err != nil {
+			t.Errorf("cronField(%q) failed: %s", test.field, err)
+			continue
+		}
+		want := make(map[int]bool)
+		for _, value := range test.want {
+			want[value] = true
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("cronField(%q) = %v, want %v", test.field, got, want)
+		}
+	}
+
+	for _, field := range []string{"0", "8", "FRI-SUN", "*/0", "L", "2#1", "MONDAY"} {
+		if got, err := cronField(field, 1, 7, cronDaysOfWeek); err == nil {
+			t.Errorf("cronField(%q) = %v, want an error", field, got)
+		}
+	}
+	for _, field := range []string{"60", "0-60", "-1"} {
+		if got, err := cronField(field, 0, 59, nil); err == nil {
+			t.Errorf("cronField(%q) = %v, want an error", field, got)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/awserr"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// Statuses of the secret references checked by SecretChecker
+const (
+	SecretOK      = "OK"
+	SecretMissing = "MISSING"
+	SecretDenied  = "DENIED"
+	SecretError   = "ERROR"
+)
+
+// SecretReference is a secret injected in a container from SSM Parameter Store or Secrets
+// Manager, with the result of its check
+type SecretReference struct {
+	Cluster        string `json:"cluster"`
+	Service        string `json:"service"`
+	TaskDefinition string `json:"taskDefinition"`
+	Container      string `json:"container"`
+	Name           string `json:"name"`
+	ValueFrom      string `json:"valueFrom"`
+	Status         string `json:"status"`
+	Message        string `json:"message,omitempty"`
+}
+
+// secretResource is a parameter or secret resolved from the ValueFrom of a reference
+type secretResource struct {
+	arn     string
+	action  string
+	status  string
+	message string
+}
+
+// SecretChecker verifies that the secrets referenced by task definitions exist and that their
+// execution role can read them. Resources and IAM simulations are cached across services
+type SecretChecker struct {
+	config    aws.Config
+	iam       *iam.Client
+	resources map[string]secretResource
+	decisions map[string]secretResource
+}
+
+// NewSecretChecker returns a SecretChecker using the configuration of an ECS client
+func NewSecretChecker(client *ecs.Client) *SecretChecker {
+	return &SecretChecker{
+		config:    client.Config,
+		iam:       iam.New(client.Config),
+		resources: make(map[string]secretResource),
+		decisions: make(map[string]secretResource),
+	}
+}
+
+// Check resolves the secrets referenced by the containers of a task definition, including the
+// secret options of their log configuration and their private registry credentials
+func (c *SecretChecker) Check(clusterName, serviceName string, taskDefinition *ecs.TaskDefinition) []SecretReference {
+	taskDefinitionName := fmt.Sprintf("%s:%d", *taskDefinition.Family, *taskDefinition.Revision)
+	references := make([]SecretReference, 0)
+	for _, container := range taskDefinition.ContainerDefinitions {
+		reference := SecretReference{
+			Cluster: clusterName, Service: serviceName, TaskDefinition: taskDefinitionName, Container: *container.Name,
+		}
+		for _, secret := range container.Secrets {
+			reference.Name, reference.ValueFrom = *secret.Name, *secret.ValueFrom
+			references = append(references, reference)
+		}
+		if container.LogConfiguration != nil {
+			for _, secret := range container.LogConfiguration.SecretOptions {
+				reference.Name, reference.ValueFrom = "log option "+*secret.Name, *secret.ValueFrom
+				references = append(references, reference)
+			}
+		}
+		if container.RepositoryCredentials != nil && container.RepositoryCredentials.CredentialsParameter != nil {
+			reference.Name, reference.ValueFrom = "repository credentials", *container.RepositoryCredentials.CredentialsParameter
+			references = append(references, reference)
+		}
+	}
+	for index := range references {
+		result := c.resolve(references[index].ValueFrom)
+		if result.status == SecretOK {
+			result = c.simulate(taskDefinition.ExecutionRoleArn, result)
+		}
+		references[index].Status, references[index].Message = result.status, result.message
+	}
+	return references
+}
+
+// clientConfig returns the AWS configuration for a region, the region of the ECS client being
+// used when it is empty
+func (c *SecretChecker) clientConfig(region string) aws.Config {
+	cfg := c.config.Copy()
+	if region != "" {
+		cfg.Region = region
+	}
+	return cfg
+}
+
+// resolve looks up the parameter or secret referenced by a ValueFrom, either the ARN of a
+// Secrets Manager secret (optionally followed by a JSON key, version stage and version ID), the
+// ARN of an SSM parameter or the name of an SSM parameter in the region of the task
+func (c *SecretChecker) resolve(valueFrom string) secretResource {
+	if result, ok := c.resources[valueFrom]; ok {
+		return result
+	}
+	var result secretResource
+	fields := strings.Split(valueFrom, ":")
+	switch {
+	case strings.HasPrefix(valueFrom, "arn:") && len(fields) >= 7 && fields[2] == "secretsmanager":
+		result = c.resolveSecret(fields[3], strings.Join(fields[:7], ":"))
+	case strings.HasPrefix(valueFrom, "arn:") && len(fields) >= 6 && fields[2] == "ssm":
+		name := strings.TrimPrefix(strings.Join(fields[5:], ":"), "parameter")
+		// The ARN of a parameter outside of a hierarchy has no leading slash in its name
+		if strings.Count(name, "/") == 1 {
+			name = strings.TrimPrefix(name, "/")
+		}
+		result = c.resolveParameter(fields[3], name)
+	case strings.HasPrefix(valueFrom, "arn:"):
+		result = secretResource{status: SecretError, message: "not the ARN of an SSM parameter or a Secrets Manager secret"}
+	default:
+		result = c.resolveParameter("", valueFrom)
+	}
+	c.resources[valueFrom] = result
+	return result
+}
+
+func (c *SecretChecker) resolveParameter(region, name string) secretResource {
+	client := ssm.New(c.clientConfig(region))
+	resp, err := client.GetParameterRequest(&ssm.GetParameterInput{Name: &name}).Send(context.Background())
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == ssm.ErrCodeParameterNotFound {
+			return secretResource{status: SecretMissing, message: fmt.Sprintf("SSM parameter %s not found", name)}
+		}
+		return secretResource{status: SecretError, message: "Failed to get SSM parameter: " + err.Error()}
+	}
+	return secretResource{arn: *resp.Parameter.ARN, action: "ssm:GetParameters", status: SecretOK}
+}
+
+func (c *SecretChecker) resolveSecret(region, secretID string) secretResource {
+	client := secretsmanager.New(c.clientConfig(region))
+	resp, err := client.DescribeSecretRequest(&secretsmanager.DescribeSecretInput{SecretId: &secretID}).Send(context.Background())
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == secretsmanager.ErrCodeResourceNotFoundException {
+			return secretResource{status: SecretMissing, message: fmt.Sprintf("secret %s not found", secretID)}
+		}
+		return secretResource{status: SecretError, message: "Failed to describe secret: " + err.Error()}
+	}
+	if resp.DeletedDate != nil {
+		return secretResource{status: SecretMissing, message: fmt.Sprintf("secret %s is scheduled for deletion", secretID)}
+	}
+	return secretResource{arn: *resp.ARN, action: "secretsmanager:GetSecretValue", status: SecretOK}
+}
+
+// simulate checks with the IAM policy simulator that an execution role can read a resolved
+// parameter or secret. Resource policies and KMS key policies are not taken into account
+func (c *SecretChecker) simulate(executionRoleArn *string, resource secretResource) secretResource {
+	if executionRoleArn == nil || *executionRoleArn == "" {
+		return secretResource{status: SecretDenied, message: "the task definition has no execution role to read the secret"}
+	}
+	key := *executionRoleArn + " " + resource.arn
+	if result, ok := c.decisions[key]; ok {
+		return result
+	}
+	result := secretResource{arn: resource.arn, action: resource.action, status: SecretOK}
+	resp, err := c.iam.SimulatePrincipalPolicyRequest(&iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: executionRoleArn,
+		ActionNames:     []string{resource.action},
+		ResourceArns:    []string{resource.arn},
+	}).Send(context.Background())
+	if err != nil {
+		result.status, result.message = SecretError, "Failed to simulate the execution role policies: "+err.Error()
+	} else {
+		for _, evaluation := range resp.EvaluationResults {
+			if evaluation.EvalDecision != iam.PolicyEvaluationDecisionTypeAllowed {
+				result.status = SecretDenied
+				result.message = fmt.Sprintf(
+					"execution role %s is not allowed to %s (%s)", *executionRoleArn, resource.action, evaluation.EvalDecision,
+				)
+			}
+		}
+	}
+	c.decisions[key] = result
+	return result
+}
@@ -0,0 +1,90 @@
+package aws
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+// maskedValue replaces the values of the environment variables that likely hold secrets
+const maskedValue = "********"
+
+// secretNamePattern matches the names of environment variables that likely hold secrets
+var secretNamePattern = regexp.MustCompile(`(?i)(PASSWORD|PASSWD|SECRET|TOKEN|API_?KEY|PRIVATE_?KEY|CREDENTIAL|ACCESS_?KEY)`)
+
+// AddSecretPatterns adds regular expressions matching the names of environment variables that hold secrets
+func AddSecretPatterns(patterns []string) error {
+	if len(patterns) == 0 {
+		return nil
+	}
+	for _, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid secret pattern %q: %s", pattern, err.Error())
+		}
+	}
+	pattern, err := regexp.Compile(secretNamePattern.String() + "|(?i:" + strings.Join(patterns, "|") + ")")
+	if err != nil {
+		return err
+	}
+	secretNamePattern = pattern
+	return nil
+}
+
+// LikelySecret tells whether an environment variable likely holds a secret, from its name
+func LikelySecret(name string) bool {
+	return secretNamePattern.MatchString(name)
+}
+
+// highEntropy tells whether a value looks like a random token or key
+func highEntropy(value string) bool {
+	if strings.HasPrefix(value, "arn:") {
+		return false
+	}
+	if strings.Contains(value, "://") {
+		// URLs are only secret when they embed credentials
+		return strings.Contains(value, "@")
+	}
+	if len(value) < 20 || strings.ContainsAny(value, " \t\n") {
+		return false
+	}
+	counts := make(map[rune]float64)
+	for _, r := range value {
+		counts[r]++
+	}
+	entropy := 0.0
+	length := float64(len([]rune(value)))
+	for _, count := range counts {
+		frequency := count / length
+		entropy -= frequency * math.Log2(frequency)
+	}
+	return entropy >= 4
+}
+
+// MaskSecret returns the value of an environment variable, masked if it likely holds a secret
+// from its name or from the entropy of its value
+func MaskSecret(name, value string, showSecrets bool) string {
+	if !showSecrets && (LikelySecret(name) || highEntropy(value)) {
+		return maskedValue
+	}
+	return value
+}
+
+// PrintEnvironment prints the environment variables of a container, masking the values of the
+// likely secrets, and the references of its secrets
+func PrintEnvironment(container *ecs.ContainerDefinition, showSecrets bool) {
+	if len(container.Environment) > 0 {
+		fmt.Println("  Environment:")
+		for _, env := range container.Environment {
+			fmt.Printf("   - %s: %s\n", *env.Name, MaskSecret(*env.Name, *env.Value, showSecrets))
+		}
+	}
+	if len(container.Secrets) > 0 {
+		fmt.Println("  Secrets:")
+		for _, secret := range container.Secrets {
+			fmt.Printf("   - %s: %s\n", *secret.Name, *secret.ValueFrom)
+		}
+	}
+}
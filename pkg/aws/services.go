@@ -18,12 +18,7 @@ import (
 func ListServices(client *ecs.Client, clusterName, serviceFilter, serviceType string) []ecs.Service {
 	ecsServices := []ecs.Service{}
 	serviceNames := []string{}
-	listServicesInput := ecs.ListServicesInput{Cluster: &clusterName}
-	if strings.ToLower(serviceType) == "fargate" {
-		listServicesInput.LaunchType = "FARGATE"
-	} else if strings.ToLower(serviceType) == "ec2" {
-		listServicesInput.LaunchType = "EC2"
-	}
+	listServicesInput := ecs.ListServicesInput{Cluster: &clusterName, LaunchType: launchType(serviceType)}
 	req := client.ListServicesRequest(&listServicesInput)
 
 	pager := ecs.NewListServicesPaginator(req)
@@ -49,7 +44,11 @@ func ListServices(client *ecs.Client, clusterName, serviceFilter, serviceType st
 
 // DescribeServices describes a list of services running in the ECS cluster
 func DescribeServices(client *ecs.Client, clusterName string, services []string) []ecs.Service {
-	params := ecs.DescribeServicesInput{Cluster: &clusterName, Services: services}
+	params := ecs.DescribeServicesInput{
+		Cluster:  &clusterName,
+		Services: services,
+		Include:  []ecs.ServiceField{ecs.ServiceFieldTags},
+	}
 	resp, err := client.DescribeServicesRequest(&params).Send(context.Background())
 	if err != nil {
 		fmt.Println("Failed to describe services: " + err.Error())
@@ -73,6 +72,56 @@ func FindService(client *ecs.Client, cluster, service string) (ecs.Service, erro
 	return runningServices[0], nil
 }
 
+const (
+	// PausedDesiredCountTag is the service tag recording the DesiredCount of a paused service
+	PausedDesiredCountTag = "ecs-paused-desired-count"
+	// PausedAutoScalingTag is the service tag recording that the scaling activities of a paused service were suspended
+	PausedAutoScalingTag = "ecs-paused-autoscaling"
+)
+
+// UpdateDesiredCount sets the DesiredCount of an ECS service
+func UpdateDesiredCount(client *ecs.Client, clusterName, serviceName string, desiredCount int64) error {
+	_, err := client.UpdateServiceRequest(&ecs.UpdateServiceInput{
+		Cluster:      &clusterName,
+		Service:      &serviceName,
+		DesiredCount: &desiredCount,
+	}).Send(context.Background())
+	return err
+}
+
+// FindServiceTag finds a specific tag of an ECS service
+func FindServiceTag(service *ecs.Service, key string) (string, bool) {
+	for _, tag := range service.Tags {
+		if *tag.Key == key {
+			return *tag.Value, true
+		}
+	}
+	return "", false
+}
+
+// TagService sets tags on an ECS service
+func TagService(client *ecs.Client, service *ecs.Service, tags map[string]string) error {
+	ecsTags := make([]ecs.Tag, 0)
+	for key, value := range tags {
+		k, v := key, value
+		ecsTags = append(ecsTags, ecs.Tag{Key: &k, Value: &v})
+	}
+	_, err := client.TagResourceRequest(&ecs.TagResourceInput{
+		ResourceArn: service.ServiceArn,
+		Tags:        ecsTags,
+	}).Send(context.Background())
+	return err
+}
+
+// UntagService removes tags from an ECS service
+func UntagService(client *ecs.Client, service *ecs.Service, keys ...string) error {
+	_, err := client.UntagResourceRequest(&ecs.UntagResourceInput{
+		ResourceArn: service.ServiceArn,
+		TagKeys:     keys,
+	}).Send(context.Background())
+	return err
+}
+
 func serviceUp(service *ecs.Service) bool {
 	return *service.DesiredCount == *service.RunningCount &&
 		len(service.Events) > 0 &&
@@ -99,17 +148,26 @@ func ServiceOk(service *ecs.Service) bool {
 
 // ServiceTaskDefinition returns a full task definition from its ARN
 func ServiceTaskDefinition(client *ecs.Client, taskDefinition string) ecs.TaskDefinition {
-	resp, err := client.DescribeTaskDefinitionRequest(
-		&ecs.DescribeTaskDefinitionInput{TaskDefinition: &taskDefinition}).Send(context.Background())
+	definition, _ := DescribeTaskDefinition(client, taskDefinition)
+	return definition
+}
+
+// DescribeTaskDefinition returns a full task definition from its ARN, with its tags
+func DescribeTaskDefinition(client *ecs.Client, taskDefinition string) (ecs.TaskDefinition, []ecs.Tag) {
+	resp, err := client.DescribeTaskDefinitionRequest(&ecs.DescribeTaskDefinitionInput{
+		TaskDefinition: &taskDefinition,
+		Include:        []ecs.TaskDefinitionField{ecs.TaskDefinitionFieldTags},
+	}).Send(context.Background())
 	if err != nil {
 		fmt.Println("Failed to describe task definition: " + err.Error())
 		os.Exit(1)
 	}
-	return *resp.TaskDefinition
+	return *resp.TaskDefinition, resp.Tags
 }
 
-// PrintServiceDetails describes an ECS service to fetch detailed information
-func PrintServiceDetails(client *ecs.Client, service *ecs.Service, longOutput bool) {
+// PrintServiceDetails describes an ECS service to fetch detailed information, the values of the
+// environment variables that likely hold secrets being masked unless showSecrets is set
+func PrintServiceDetails(client *ecs.Client, service *ecs.Service, longOutput, showSecrets bool) {
 	elbClient := elasticloadbalancingv2.New(client.Config)
 	fmt.Printf(
 		"%-15s  %-70s %-7s %-8s running %d/%d  (%s)\n",
@@ -123,6 +181,10 @@ func PrintServiceDetails(client *ecs.Client, service *ecs.Service, longOutput bo
 		if taskDefinition.TaskRoleArn != nil {
 			fmt.Printf("IAM Role: %s\n", linkToIAM(shortTaskDefinitionName(*taskDefinition.TaskRoleArn)))
 		}
+		printAutoScaling(client, clusterNameFromArn(*service.ClusterArn), *service.ServiceName)
+		if len(service.CapacityProviderStrategy) > 0 {
+			fmt.Printf("Capacity Provider Strategy: %s\n", FormatCapacityProviderStrategy(service.CapacityProviderStrategy))
+		}
 
 		for _, lb := range service.LoadBalancers {
 			response, err := elbClient.DescribeTargetGroupsRequest(&elasticloadbalancingv2.DescribeTargetGroupsInput{
@@ -177,12 +239,7 @@ func PrintServiceDetails(client *ecs.Client, service *ecs.Service, longOutput bo
 					fmt.Printf("   - %s: %s\n", name, option)
 				}
 			}
-			if len(container.Environment) > 0 {
-				fmt.Println("  Environment:")
-				for _, env := range container.Environment {
-					fmt.Printf("   - %s: %s\n", *env.Name, *env.Value)
-				}
-			}
+			PrintEnvironment(&container, showSecrets)
 		}
 		fmt.Println()
 	}
@@ -0,0 +1,259 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+// Snapshot captures the state of the ECS clusters of an account at a point in time
+type Snapshot struct {
+	CreatedAt     time.Time         `json:"createdAt"`
+	Region        string            `json:"region"`
+	ClusterFilter string            `json:"clusterFilter,omitempty"`
+	Clusters      []ClusterSnapshot `json:"clusters"`
+}
+
+// ClusterSnapshot captures the state of an ECS cluster
+type ClusterSnapshot struct {
+	Name      string             `json:"name"`
+	Services  []ServiceSnapshot  `json:"services"`
+	Instances []InstanceSnapshot `json:"instances"`
+}
+
+// ServiceSnapshot captures the state of an ECS service
+type ServiceSnapshot struct {
+	Name           string               `json:"name"`
+	TaskDefinition string               `json:"taskDefinition"`
+	DesiredCount   int64                `json:"desiredCount"`
+	Images         map[string]string    `json:"images"`
+	AutoScaling    *AutoScalingManifest `json:"autoscaling,omitempty"`
+}
+
+// InstanceSnapshot captures the state of a container instance
+type InstanceSnapshot struct {
+	InstanceID   string `json:"instanceId"`
+	InstanceType string `json:"instanceType"`
+	Status       string `json:"status"`
+}
+
+// Drift is a difference between a snapshot and the live state of an ECS cluster
+type Drift struct {
+	Cluster  string
+	Action   string
+	Resource string
+	Details  string
+}
+
+// TakeSnapshot captures the state of the ECS clusters matching a filter
+func TakeSnapshot(client *ecs.Client, clusterFilter string) Snapshot {
+	snapshot := Snapshot{
+		CreatedAt:     time.Now().UTC(),
+		Region:        client.Config.Region,
+		ClusterFilter: clusterFilter,
+		Clusters:      make([]ClusterSnapshot, 0),
+	}
+	clusterNames := ListClusters(client, clusterFilter)
+	for _, cluster := range DescribeClusters(client, clusterNames) {
+		clusterSnapshot := ClusterSnapshot{
+			Name:      *cluster.ClusterName,
+			Services:  make([]ServiceSnapshot, 0),
+			Instances: make([]InstanceSnapshot, 0),
+		}
+		for _, service := range ListServices(client, *cluster.ClusterName, "", "") {
+			serviceSnapshot := ServiceSnapshot{
+				Name:           *service.ServiceName,
+				TaskDefinition: shortTaskDefinitionName(*service.TaskDefinition),
+				DesiredCount:   *service.DesiredCount,
+				Images:         make(map[string]string),
+			}
+			taskDefinition := ServiceTaskDefinition(client, *service.TaskDefinition)
+			for _, container := range taskDefinition.ContainerDefinitions {
+				serviceSnapshot.Images[*container.Name] = *container.Image
+			}
+			if target := ScalableTarget(client, *cluster.ClusterName, *service.ServiceName); target != nil {
+				serviceSnapshot.AutoScaling = &AutoScalingManifest{Min: target.MinCapacity, Max: target.MaxCapacity}
+			}
+			clusterSnapshot.Services = append(clusterSnapshot.Services, serviceSnapshot)
+		}
+		for _, containerInstance := range ListContainerInstances(client, *cluster.ClusterName) {
+			attribute := FindAttribute(containerInstance.Attributes, "ecs.instance-type")
+			instanceType := ""
+			if attribute.Value != nil {
+				instanceType = *attribute.Value
+			}
+			clusterSnapshot.Instances = append(clusterSnapshot.Instances, InstanceSnapshot{
+				InstanceID:   *containerInstance.Ec2InstanceId,
+				InstanceType: instanceType,
+				Status:       *containerInstance.Status,
+			})
+		}
+		sort.Slice(clusterSnapshot.Instances, func(i, j int) bool {
+			return clusterSnapshot.Instances[i].InstanceID < clusterSnapshot.Instances[j].InstanceID
+		})
+		snapshot.Clusters = append(snapshot.Clusters, clusterSnapshot)
+	}
+	return snapshot
+}
+
+// WriteSnapshot writes a snapshot to a JSON file
+func WriteSnapshot(snapshot Snapshot, path string) error {
+	content, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(content, '\n'), 0644)
+}
+
+// ReadSnapshot reads a snapshot from a JSON file
+func ReadSnapshot(path string) (Snapshot, error) {
+	var snapshot Snapshot
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return snapshot, err
+	}
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("invalid snapshot %s: %s", path, err.Error())
+	}
+	return snapshot, nil
+}
+
+func formatAutoScaling(autoScaling *AutoScalingManifest) string {
+	if autoScaling == nil || autoScaling.Min == nil || autoScaling.Max == nil {
+		return "none"
+	}
+	return fmt.Sprintf("min %d / max %d", *autoScaling.Min, *autoScaling.Max)
+}
+
+// CompareSnapshots returns the differences between a saved snapshot and the live state,
+// "+" being used for added resources, "-" for removed ones and "~" for changed ones
+func CompareSnapshots(saved, live Snapshot) []Drift {
+	drifts := make([]Drift, 0)
+	liveClusters := make(map[string]ClusterSnapshot)
+	for _, cluster := range live.Clusters {
+		liveClusters[cluster.Name] = cluster
+	}
+	savedClusters := make(map[string]bool)
+	for _, savedCluster := range saved.Clusters {
+		savedClusters[savedCluster.Name] = true
+		liveCluster, ok := liveClusters[savedCluster.Name]
+		if !ok {
+			drifts = append(drifts, Drift{Cluster: savedCluster.Name, Action: "-", Resource: "cluster " + savedCluster.Name})
+			continue
+		}
+		drifts = append(drifts, compareServices(savedCluster, liveCluster)...)
+		drifts = append(drifts, compareInstances(savedCluster, liveCluster)...)
+	}
+	for _, liveCluster := range live.Clusters {
+		if !savedClusters[liveCluster.Name] {
+			drifts = append(drifts, Drift{Cluster: liveCluster.Name, Action: "+", Resource: "cluster " + liveCluster.Name})
+		}
+	}
+	return drifts
+}
+
+func compareServices(saved, live ClusterSnapshot) []Drift {
+	drifts := make([]Drift, 0)
+	liveServices := make(map[string]ServiceSnapshot)
+	for _, service := range live.Services {
+		liveServices[service.Name] = service
+	}
+	savedServices := make(map[string]bool)
+	for _, savedService := range saved.Services {
+		savedServices[savedService.Name] = true
+		resource := "service " + savedService.Name
+		liveService, ok := liveServices[savedService.Name]
+		if !ok {
+			drifts = append(drifts, Drift{Cluster: saved.Name, Action: "-", Resource: resource})
+			continue
+		}
+		if savedService.DesiredCount != liveService.DesiredCount {
+			drifts = append(drifts, Drift{
+				Cluster: saved.Name, Action: "~", Resource: resource,
+				Details: fmt.Sprintf("desiredCount: %d -> %d", savedService.DesiredCount, liveService.DesiredCount),
+			})
+		}
+		if formatAutoScaling(savedService.AutoScaling) != formatAutoScaling(liveService.AutoScaling) {
+			drifts = append(drifts, Drift{
+				Cluster: saved.Name, Action: "~", Resource: resource,
+				Details: fmt.Sprintf(
+					"autoscaling: %s -> %s", formatAutoScaling(savedService.AutoScaling), formatAutoScaling(liveService.AutoScaling),
+				),
+			})
+		}
+		if savedService.TaskDefinition != liveService.TaskDefinition {
+			drifts = append(drifts, Drift{
+				Cluster: saved.Name, Action: "~", Resource: resource,
+				Details: fmt.Sprintf("taskDefinition: %s -> %s", savedService.TaskDefinition, liveService.TaskDefinition),
+			})
+		}
+		containers := make([]string, 0)
+		for container := range savedService.Images {
+			containers = append(containers, container)
+		}
+		for container := range liveService.Images {
+			if _, ok := savedService.Images[container]; !ok {
+				containers = append(containers, container)
+			}
+		}
+		sort.Strings(containers)
+		for _, container := range containers {
+			savedImage, liveImage := savedService.Images[container], liveService.Images[container]
+			if savedImage == liveImage {
+				continue
+			}
+			if savedImage == "" {
+				savedImage = "none"
+			}
+			if liveImage == "" {
+				liveImage = "none"
+			}
+			drifts = append(drifts, Drift{
+				Cluster: saved.Name, Action: "~", Resource: resource,
+				Details: fmt.Sprintf("image of %s: %s -> %s", container, savedImage, liveImage),
+			})
+		}
+	}
+	for _, liveService := range live.Services {
+		if !savedServices[liveService.Name] {
+			drifts = append(drifts, Drift{Cluster: saved.Name, Action: "+", Resource: "service " + liveService.Name})
+		}
+	}
+	return drifts
+}
+
+func compareInstances(saved, live ClusterSnapshot) []Drift {
+	drifts := make([]Drift, 0)
+	liveInstances := make(map[string]InstanceSnapshot)
+	for _, instance := range live.Instances {
+		liveInstances[instance.InstanceID] = instance
+	}
+	savedInstances := make(map[string]bool)
+	for _, savedInstance := range saved.Instances {
+		savedInstances[savedInstance.InstanceID] = true
+		resource := "instance " + savedInstance.InstanceID
+		liveInstance, ok := liveInstances[savedInstance.InstanceID]
+		if !ok {
+			drifts = append(drifts, Drift{Cluster: saved.Name, Action: "-", Resource: resource, Details: savedInstance.InstanceType})
+			continue
+		}
+		if savedInstance.Status != liveInstance.Status {
+			drifts = append(drifts, Drift{
+				Cluster: saved.Name, Action: "~", Resource: resource,
+				Details: fmt.Sprintf("status: %s -> %s", savedInstance.Status, liveInstance.Status),
+			})
+		}
+	}
+	for _, liveInstance := range live.Instances {
+		if !savedInstances[liveInstance.InstanceID] {
+			drifts = append(drifts, Drift{
+				Cluster: saved.Name, Action: "+", Resource: "instance " + liveInstance.InstanceID, Details: liveInstance.InstanceType,
+			})
+		}
+	}
+	return drifts
+}
@@ -0,0 +1,68 @@
+package aws
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+// RegisterTaskDefinition registers a new revision of a task definition in its family, with the
+// tags of the task definition it is derived from if any
+func RegisterTaskDefinition(client *ecs.Client, taskDefinition ecs.TaskDefinition, tags []ecs.Tag) (ecs.TaskDefinition, error) {
+	if len(tags) == 0 {
+		tags = nil
+	}
+	resp, err := client.RegisterTaskDefinitionRequest(&ecs.RegisterTaskDefinitionInput{
+		ContainerDefinitions:    taskDefinition.ContainerDefinitions,
+		Cpu:                     taskDefinition.Cpu,
+		ExecutionRoleArn:        taskDefinition.ExecutionRoleArn,
+		Family:                  taskDefinition.Family,
+		InferenceAccelerators:   taskDefinition.InferenceAccelerators,
+		IpcMode:                 taskDefinition.IpcMode,
+		Memory:                  taskDefinition.Memory,
+		NetworkMode:             taskDefinition.NetworkMode,
+		PidMode:                 taskDefinition.PidMode,
+		PlacementConstraints:    taskDefinition.PlacementConstraints,
+		ProxyConfiguration:      taskDefinition.ProxyConfiguration,
+		RequiresCompatibilities: taskDefinition.RequiresCompatibilities,
+		Tags:                    tags,
+		TaskRoleArn:             taskDefinition.TaskRoleArn,
+		Volumes:                 taskDefinition.Volumes,
+	}).Send(context.Background())
+	if err != nil {
+		return ecs.TaskDefinition{}, err
+	}
+	return *resp.TaskDefinition, nil
+}
+
+// ContainerEnvironment returns the environment variables of a container definition
+func ContainerEnvironment(container *ecs.ContainerDefinition) map[string]string {
+	environment := make(map[string]string)
+	for _, variable := range container.Environment {
+		environment[*variable.Name] = *variable.Value
+	}
+	return environment
+}
+
+// SetContainerEnvironment replaces the environment variables of a container definition
+func SetContainerEnvironment(container *ecs.ContainerDefinition, environment map[string]string) {
+	names := make([]string, 0, len(environment))
+	for name := range environment {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	container.Environment = make([]ecs.KeyValuePair, 0, len(names))
+	for _, name := range names {
+		n, v := name, environment[name]
+		container.Environment = append(container.Environment, ecs.KeyValuePair{Name: &n, Value: &v})
+	}
+}
+
+// copyTaskDefinition returns a copy of a task definition whose container definitions can be modified
+func copyTaskDefinition(taskDefinition ecs.TaskDefinition) ecs.TaskDefinition {
+	containers := make([]ecs.ContainerDefinition, len(taskDefinition.ContainerDefinitions))
+	copy(containers, taskDefinition.ContainerDefinitions)
+	taskDefinition.ContainerDefinitions = containers
+	return taskDefinition
+}
@@ -6,7 +6,9 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws/awserr"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	"github.com/fatih/color"
 )
@@ -17,9 +19,54 @@ func (c byTaskName) Len() int           { return len(c) }
 func (c byTaskName) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
 func (c byTaskName) Less(i, j int) bool { return *c[i].TaskDefinitionArn < *c[j].TaskDefinitionArn }
 
+// TaskFilter holds the criteria used to filter the tasks of an ECS cluster
+type TaskFilter struct {
+	Service           string
+	Family            string
+	Status            string
+	LaunchType        string
+	ContainerInstance string
+	StartedBy         string
+}
+
 // ListTasks gives a short list of ECS tasks
-func ListTasks(client *ecs.Client, clusterName, taskFilter string) []ecs.Task {
-	req := client.ListTasksRequest(&ecs.ListTasksInput{Cluster: &clusterName})
+func ListTasks(client *ecs.Client, clusterName string, filter TaskFilter) []ecs.Task {
+	ecsTasks := make([]ecs.Task, 0)
+	input := ecs.ListTasksInput{Cluster: &clusterName, LaunchType: launchType(filter.LaunchType)}
+	if filter.Service != "" {
+		input.ServiceName = &filter.Service
+	}
+	if filter.Family != "" {
+		input.Family = &filter.Family
+	}
+	// ECS never sets the desired status of a task to PENDING, pending tasks are
+	// running tasks whose last status is still PENDING
+	status := strings.ToUpper(filter.Status)
+	switch status {
+	case "STOPPED":
+		input.DesiredStatus = ecs.DesiredStatusStopped
+	case "RUNNING", "PENDING":
+		input.DesiredStatus = ecs.DesiredStatusRunning
+	}
+	if filter.ContainerInstance != "" {
+		containerInstance := filter.ContainerInstance
+		if strings.HasPrefix(containerInstance, "i-") {
+			containerInstance = ContainerInstanceArn(client, clusterName, containerInstance)
+			if containerInstance == "" {
+				return ecsTasks
+			}
+		}
+		input.ContainerInstance = &containerInstance
+	}
+	// The API rejects startedBy combined with any other filter, the tasks are then filtered on
+	// their startedBy value once described
+	filterStartedBy := filter.StartedBy != "" && (input.ServiceName != nil || input.Family != nil ||
+		input.ContainerInstance != nil || input.LaunchType != "" || input.DesiredStatus != "")
+	if filter.StartedBy != "" && !filterStartedBy {
+		input.StartedBy = &filter.StartedBy
+	}
+
+	req := client.ListTasksRequest(&input)
 	p := ecs.NewListTasksPaginator(req)
 
 	taskNames := make([]string, 0)
@@ -27,39 +74,136 @@ func ListTasks(client *ecs.Client, clusterName, taskFilter string) []ecs.Task {
 		page := p.CurrentPage()
 		taskNames = append(taskNames, page.TaskArns...)
 	}
+	if err := p.Err(); err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == ecs.ErrCodeServiceNotFoundException {
+			return ecsTasks
+		}
+		fmt.Println("Failed to list tasks: " + err.Error())
+		os.Exit(1)
+	}
 
+	for _, t := range DescribeTasks(client, clusterName, taskNames) {
+		if status == "PENDING" && *t.LastStatus != status {
+			continue
+		}
+		if filterStartedBy && (t.StartedBy == nil || *t.StartedBy != filter.StartedBy) {
+			continue
+		}
+		ecsTasks = append(ecsTasks, t)
+	}
+	sort.Sort(byTaskName(ecsTasks))
+	return ecsTasks
+}
+
+// DescribeTasks describes a list of tasks running in the ECS cluster
+func DescribeTasks(client *ecs.Client, clusterName string, tasks []string) []ecs.Task {
 	ecsTasks := make([]ecs.Task, 0)
-	for _, tasks := range chunk(taskNames, 100) {
-		if len(tasks) > 0 {
-			for _, t := range describeTasks(client, clusterName, tasks) {
-				if strings.Contains(*t.TaskDefinitionArn, taskFilter) {
-					ecsTasks = append(ecsTasks, t)
-				}
+	for _, arns := range chunk(tasks, 100) {
+		if len(arns) > 0 {
+			params := ecs.DescribeTasksInput{Cluster: &clusterName, Tasks: arns}
+			resp, err := client.DescribeTasksRequest(&params).Send(context.Background())
+			if err != nil {
+				fmt.Println("Failed to describe tasks: " + err.Error())
+				os.Exit(1)
 			}
+			ecsTasks = append(ecsTasks, resp.Tasks...)
 		}
 	}
-	sort.Sort(byTaskName(ecsTasks))
 	return ecsTasks
 }
 
-func describeTasks(client *ecs.Client, clusterName string, tasks []string) []ecs.Task {
-	params := ecs.DescribeTasksInput{Cluster: &clusterName, Tasks: tasks}
-	resp, err := client.DescribeTasksRequest(&params).Send(context.Background())
+// DescribeTask returns the current state of an ECS task
+func DescribeTask(client *ecs.Client, clusterName, taskArn string) ecs.Task {
+	tasks := DescribeTasks(client, clusterName, []string{taskArn})
+	if len(tasks) == 0 {
+		fmt.Printf("Task %s not found in cluster %s\n", taskArn, clusterName)
+		os.Exit(1)
+	}
+	return tasks[0]
+}
+
+// RunTask starts a new task in an ECS cluster
+func RunTask(client *ecs.Client, params *ecs.RunTaskInput) ecs.Task {
+	resp, err := client.RunTaskRequest(params).Send(context.Background())
 	if err != nil {
-		fmt.Println("Failed to describe services: " + err.Error())
+		fmt.Println("Failed to run task: " + err.Error())
 		os.Exit(1)
 	}
-	return resp.Tasks
+	if len(resp.Failures) > 0 {
+		for _, failure := range resp.Failures {
+			fmt.Println("Failed to run task: " + *failure.Reason)
+		}
+		os.Exit(1)
+	}
+	return resp.Tasks[0]
+}
+
+// StopTask stops a running task with the given reason
+func StopTask(client *ecs.Client, clusterName, taskArn, reason string) error {
+	_, err := client.StopTaskRequest(&ecs.StopTaskInput{
+		Cluster: &clusterName,
+		Task:    &taskArn,
+		Reason:  &reason,
+	}).Send(context.Background())
+	return err
+}
+
+// taskNetwork returns the private IP address and the network interface of a task
+// from its ENI attachment, which is only available for tasks using the awsvpc network mode
+func taskNetwork(task *ecs.Task) (string, string) {
+	var ipAddress, eni = "-", "-"
+	for _, attachment := range task.Attachments {
+		if *attachment.Type != "ElasticNetworkInterface" {
+			continue
+		}
+		for _, detail := range attachment.Details {
+			switch *detail.Name {
+			case "privateIPv4Address":
+				ipAddress = *detail.Value
+			case "networkInterfaceId":
+				eni = *detail.Value
+			}
+		}
+	}
+	return ipAddress, eni
 }
 
-// PrintTaskDetails prints detailed information about an ECS task
-func PrintTaskDetails(client *ecs.Client, task *ecs.Task, longOutput bool) {
-	var status = *task.LastStatus
+// PrintTaskDetails prints detailed information about an ECS task, instanceID being the
+// EC2 instance the task is placed on (empty for Fargate tasks). The values of the environment
+// variables that likely hold secrets are masked unless showSecrets is set
+func PrintTaskDetails(client *ecs.Client, task *ecs.Task, instanceID string, longOutput, showSecrets bool) {
+	var status = fmt.Sprintf("%-10s", *task.LastStatus)
 	if *task.LastStatus == "PENDING" {
-		status = color.YellowString(status) + "   "
+		status = color.YellowString(status)
+	}
+	var health = fmt.Sprintf("%-9s", task.HealthStatus)
+	switch task.HealthStatus {
+	case ecs.HealthStatusHealthy:
+		health = color.GreenString(health)
+	case ecs.HealthStatusUnhealthy:
+		health = color.RedString(health)
 	}
+	var (
+		availabilityZone = "-"
+		placement        = "-"
+		age              = "-"
+	)
+	if task.AvailabilityZone != nil {
+		availabilityZone = *task.AvailabilityZone
+	}
+	if instanceID != "" {
+		placement = instanceID
+	} else if task.LaunchType == ecs.LaunchTypeFargate && task.PlatformVersion != nil {
+		placement = "FARGATE " + *task.PlatformVersion
+	}
+	if task.StartedAt != nil {
+		age = humanizeDuration(time.Since(*task.StartedAt))
+	}
+	ipAddress, eni := taskNetwork(task)
 	fmt.Printf(
-		"%-70s  %-10s", shortTaskDefinitionName(*task.TaskDefinitionArn), status,
+		"%-36s  %-45s  %s  %s  %-15s  %-21s  %-11s  %-19s  %6s",
+		TaskIDFromArn(*task.TaskArn), shortTaskDefinitionName(*task.TaskDefinitionArn),
+		status, health, ipAddress, eni, availabilityZone, placement, age,
 	)
 	if task.Cpu != nil {
 		fmt.Printf("  Cpu: %4s", *task.Cpu)
@@ -83,12 +227,7 @@ func PrintTaskDetails(client *ecs.Client, task *ecs.Task, longOutput bool) {
 					)
 				}
 			}
-			if len(container.Environment) > 0 {
-				fmt.Println("  Environment:")
-				for _, env := range container.Environment {
-					fmt.Printf("   - %s: %s\n", *env.Name, *env.Value)
-				}
-			}
+			PrintEnvironment(&container, showSecrets)
 			if len(container.Links) > 0 {
 				fmt.Printf("  Links: %s\n", strings.Join(container.Links, ","))
 			}